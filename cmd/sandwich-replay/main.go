@@ -0,0 +1,225 @@
+// Command sandwich-replay replays recorded sandwich_daemon.ProducedPayload streams through
+// the event parsers, reporting any payloads that fail to be handled. Recordings can be plain
+// or gzip compressed JSON lines.
+//
+//	sandwich-replay [flags] recording.jsonl...
+//
+// Every identifier in the recordings is given a bot without listeners, which only counts the
+// errors of the parsers. To replay through the listeners of a bot, call Sandwich.Replay from
+// a program that registers them.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich_protobuf "github.com/WelcomerTeam/Sandwich-Daemon/proto"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const usage = `usage: sandwich-replay [flags] recording.jsonl...
+
+Payloads are dispatched to bots without listeners, so only the event parsers are run.
+To replay through the listeners of a bot, call Sandwich.Replay from a program that
+registers them.
+
+`
+
+var (
+	grpcAddress  = flag.String("grpc", "", "address of Sandwich Daemon to fetch applications from. If empty, applications are not fetched")
+	realTime     = flag.Bool("realtime", false, "wait between payloads for as long as when they were published")
+	speed        = flag.Float64("speed", 1, "speed multiplier when replaying in real time")
+	events       = flag.String("events", "", "comma separated event types to replay")
+	guilds       = flag.String("guilds", "", "comma separated guild IDs to replay")
+	stopOnError  = flag.Bool("stop-on-error", false, "stop at the first payload that fails to be handled")
+	drainTimeout = flag.Duration("drain-timeout", time.Minute, "how long to wait for queued events once replaying has finished")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Replaying stops on SIGINT or SIGTERM, and the queued events are still drained.
+	ctx, stop := sandwich.SignalContext(context.Background())
+
+	err := run(ctx, flag.Args())
+
+	stop()
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run replays the recordings at paths and reports what was replayed.
+func run(ctx context.Context, paths []string) error {
+	options := sandwich.ReplayOptions{
+		RealTime:         *realTime,
+		Speed:            *speed,
+		StopOnFirstError: *stopOnError,
+	}
+
+	if *events != "" {
+		options.EventTypes = strings.Split(*events, ",")
+	}
+
+	if *guilds != "" {
+		for guild := range strings.SplitSeq(*guilds, ",") {
+			guildID, err := strconv.ParseInt(strings.TrimSpace(guild), 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid guild ID %q: %w", guild, err)
+			}
+
+			options.GuildIDs = append(options.GuildIDs, discord.Snowflake(guildID))
+		}
+	}
+
+	var conn grpc.ClientConnInterface
+
+	if *grpcAddress != "" {
+		clientConn, err := grpc.NewClient(*grpcAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return fmt.Errorf("failed to connect to sandwich daemon: %w", err)
+		}
+
+		defer clientConn.Close()
+
+		conn = clientConn
+	}
+
+	restInterface := discord.NewBaseInterface()
+
+	sandwichClient := sandwich.NewSandwich(conn, &restInterface, os.Stderr)
+
+	var handlerErrors atomic.Int64
+
+	for _, path := range paths {
+		err := registerRecordedBots(sandwichClient, path, *grpcAddress == "", &handlerErrors)
+		if err != nil {
+			return err
+		}
+	}
+
+	total := sandwich.ReplayStats{}
+
+	var replayErr error
+
+	for _, path := range paths {
+		stats, err := replayFile(ctx, sandwichClient, path, options)
+
+		total.Read += stats.Read
+		total.Dispatched += stats.Dispatched
+		total.Ignored += stats.Ignored
+		total.Skipped += stats.Skipped
+		total.Malformed += stats.Malformed
+
+		if err != nil {
+			replayErr = fmt.Errorf("%s: %w", path, err)
+
+			break
+		}
+	}
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), *drainTimeout)
+	defer drainCancel()
+
+	abandoned, _ := sandwichClient.Shutdown(drainCtx)
+
+	fmt.Fprintf(os.Stderr, "read %d, dispatched %d, ignored %d, skipped %d, malformed %d, handler errors %d, abandoned %d\n",
+		total.Read, total.Dispatched, total.Ignored, total.Skipped, total.Malformed, handlerErrors.Load(), abandoned)
+
+	// Being stopped by a signal is not a failure.
+	if errors.Is(replayErr, context.Canceled) {
+		return nil
+	}
+
+	return replayErr
+}
+
+func replayFile(ctx context.Context, sandwichClient *sandwich.Sandwich, path string, options sandwich.ReplayOptions) (sandwich.ReplayStats, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return sandwich.ReplayStats{}, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	return sandwichClient.Replay(ctx, file, options)
+}
+
+// registerRecordedBots registers a bot for every identifier in a recording, which counts
+// the errors passed to its ERROR handlers. If stubApplications is set, every application
+// in the recording is given an empty identifier so it is not fetched from Sandwich Daemon.
+func registerRecordedBots(sandwichClient *sandwich.Sandwich, path string, stubApplications bool, handlerErrors *atomic.Int64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := sandwich.NewRecordingReader(file)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), sandwich.MaxReplayLineSize)
+
+	for scanner.Scan() {
+		var payload struct {
+			Metadata sandwich_daemon.ProducedMetadata `json:"__metadata"`
+		}
+
+		if json.Unmarshal(scanner.Bytes(), &payload) != nil {
+			continue
+		}
+
+		if _, ok := sandwichClient.Bots[payload.Metadata.Identifier]; !ok {
+			bot := sandwich.NewBot(sandwichClient.Logger)
+
+			bot.RegisterOnError(func(eventCtx *sandwich.EventContext, err error) error {
+				handlerErrors.Add(1)
+				eventCtx.Logger.Error("Failed to handle replayed event", "error", err)
+
+				return nil
+			})
+
+			sandwichClient.RegisterBot(payload.Metadata.Identifier, bot)
+		}
+
+		if _, ok := sandwichClient.Identifiers[payload.Metadata.Application]; stubApplications && !ok && payload.Metadata.Application != "" {
+			sandwichClient.Identifiers[payload.Metadata.Application] = &sandwich_protobuf.SandwichApplication{
+				ApplicationIdentifier: payload.Metadata.Application,
+			}
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	return nil
+}
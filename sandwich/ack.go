@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+)
+
+const defaultMaxInFlight = 256
+
+// WithMaxInFlight limits how many messages received by ListenToMessages can be handled
+// at once. No more messages are received until earlier ones are acknowledged.
+// A value of 0 or less removes the limit. Defaults to 256.
+func WithMaxInFlight(limit int) ListenOption {
+	return func(options *listenOptions) {
+		options.maxInFlight = limit
+	}
+}
+
+// receiveMessages dispatches messages with dispatchCtx until the context is done. Returns
+// ErrChannelClosed if the channel is closed.
+func (sandwich *Sandwich) receiveMessages(ctx, dispatchCtx context.Context, options *listenOptions, messages <-chan *MQMessage) error {
+	var inFlight chan struct{}
+	if options.maxInFlight > 0 {
+		inFlight = make(chan struct{}, options.maxInFlight)
+	}
+
+	release := func() {
+		if inFlight != nil {
+			<-inFlight
+		}
+	}
+
+	for {
+		if inFlight != nil {
+			select {
+			case inFlight <- struct{}{}:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		select {
+		case message, ok := <-messages:
+			if !ok {
+				return ErrChannelClosed
+			}
+
+			sandwich.dispatchMessage(dispatchCtx, options.recorder, message, release)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// dispatchMessage dispatches a message to the bot of its identifier and acknowledges it
+// once it has been handled, then calls release.
+func (sandwich *Sandwich) dispatchMessage(ctx context.Context, recorder Recorder, message *MQMessage, release func()) {
+	sandwich.recordMessage(recorder, message.Data)
+
+	var payload sandwich_daemon.ProducedPayload
+
+	err := json.Unmarshal(message.Data, &payload)
+	if err != nil {
+		sandwich.Logger.Warn("Failed to unmarshal mq message", "error", err)
+
+		sandwich.deadLetter(ctx, &DeadLetter{
+			Reason:   DeadLetterUnmarshalFailed,
+			Error:    err.Error(),
+			Raw:      message.Data,
+			FailedAt: time.Now(),
+		})
+
+		sandwich.settleMessage(ctx, message, nil)
+		release()
+
+		return
+	}
+
+	bot, err := sandwich.payloadBot(payload)
+	if bot == nil {
+		result := &DispatchResult{Event: payload.Type, Ignored: true}
+
+		// Events without a bot are only delivered again if ErrorOnInvalidIdentifier is set.
+		if err != nil {
+			sandwich.Logger.Warn("Failed to dispatch sandwich payload", "error", err)
+
+			result.Dropped = true
+		}
+
+		sandwich.settleMessage(ctx, message, result)
+		release()
+
+		return
+	}
+
+	// The dispatch is pending until the worker has handled it and every retry of its listeners has finished.
+	eventCtx := sandwich.newEventContext(ctx, bot.Handlers, &payload)
+	eventCtx.dispatchErrors = &dispatchErrors{pending: 1}
+
+	var once sync.Once
+
+	eventCtx.onDispatched = func(result *DispatchResult) {
+		once.Do(func() {
+			sandwich.settleMessage(ctx, message, result)
+			release()
+		})
+	}
+
+	bot.Dispatch(eventCtx, payload)
+}
+
+// settleMessage acknowledges a message if it was handled, or negatively acknowledges it
+// if it was dropped or failed.
+func (sandwich *Sandwich) settleMessage(ctx context.Context, message *MQMessage, result *DispatchResult) {
+	// Messages are still settled once the listener has stopped.
+	ctx = context.WithoutCancel(ctx)
+
+	if result != nil && (result.Dropped || result.Err() != nil) {
+		if message.Nack == nil {
+			return
+		}
+
+		err := message.Nack(ctx)
+		if err != nil {
+			sandwich.Logger.Warn("Failed to nack message", "event", result.Event, "error", err)
+		}
+
+		return
+	}
+
+	if message.Ack == nil {
+		return
+	}
+
+	err := message.Ack(ctx)
+	if err != nil {
+		sandwich.Logger.Warn("Failed to ack message", "error", err)
+	}
+}
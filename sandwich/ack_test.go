@@ -0,0 +1,303 @@
+package internal_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+)
+
+// newMQMessage creates a message that sends true to settled when it is acknowledged and
+// false when it is negatively acknowledged.
+func newMQMessage(data []byte, settled chan<- bool) *sandwich.MQMessage {
+	return &sandwich.MQMessage{
+		Data: data,
+		Ack: func(context.Context) error {
+			settled <- true
+
+			return nil
+		},
+		Nack: func(context.Context) error {
+			settled <- false
+
+			return nil
+		},
+	}
+}
+
+// listenToMessages starts ListenToMessages on the harness and returns the channel it
+// receives from. The listener is stopped when the test ends.
+func listenToMessages(t *testing.T, harness *sandwichtest.Harness, opts ...sandwich.ListenOption) chan<- *sandwich.MQMessage {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	messages := make(chan *sandwich.MQMessage)
+	listenErr := make(chan error, 1)
+
+	go func() {
+		listenErr <- harness.Sandwich.ListenToMessages(ctx, messages, opts...)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+
+		err := <-listenErr
+		if err != nil {
+			t.Errorf("ListenToMessages returned %v, want nil", err)
+		}
+	})
+
+	return messages
+}
+
+// waitSettled returns if the next message was acknowledged.
+func waitSettled(t *testing.T, settled <-chan bool) bool {
+	t.Helper()
+
+	select {
+	case acked := <-settled:
+		return acked
+	case <-time.After(time.Second * 5):
+		t.Fatal("message was not settled")
+	}
+
+	return false
+}
+
+func TestListenToMessagesSettles(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   sandwich_daemon.ProducedPayload
+		data      []byte
+		failures  int
+		policy    *sandwich.RetryPolicy
+		errorOn   bool
+		wantAcked bool
+	}{
+		{name: "handled", payload: sandwichtest.Resumed(discord.Resume{}), wantAcked: true},
+		{name: "handler failed", payload: sandwichtest.Resumed(discord.Resume{}), failures: 1, wantAcked: false},
+		{
+			name:      "retried",
+			payload:   sandwichtest.Resumed(discord.Resume{}),
+			failures:  1,
+			policy:    sandwich.NewRetryPolicy(2, time.Millisecond, 0),
+			wantAcked: true,
+		},
+		{
+			name:      "retries exhausted",
+			payload:   sandwichtest.Resumed(discord.Resume{}),
+			failures:  2,
+			policy:    sandwich.NewRetryPolicy(2, time.Millisecond, 0),
+			wantAcked: false,
+		},
+		{name: "undecodable", data: []byte("not json"), wantAcked: true},
+		{name: "no bot", payload: sandwichtest.Resumed(discord.Resume{}, sandwichtest.WithIdentifier("unknown")), wantAcked: true},
+		{
+			name:      "invalid identifier",
+			payload:   sandwichtest.Resumed(discord.Resume{}, sandwichtest.WithIdentifier("unknown")),
+			errorOn:   true,
+			wantAcked: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			harness := sandwichtest.New(t)
+			harness.Sandwich.SetErrorOnInvalidIdentifier(test.errorOn)
+
+			var calls atomic.Int32
+
+			listener := sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(*sandwich.EventContext) error {
+				if int(calls.Add(1)) <= test.failures {
+					return errTest
+				}
+
+				return nil
+			})
+
+			if test.policy != nil {
+				listener.SetRetryPolicy(test.policy)
+			}
+
+			data := test.data
+			if data == nil {
+				data = sandwichtest.Marshal(test.payload)
+			}
+
+			settled := make(chan bool, 1)
+
+			listenToMessages(t, harness) <- newMQMessage(data, settled)
+
+			if acked := waitSettled(t, settled); acked != test.wantAcked {
+				t.Errorf("message was acked: %v, want %v", acked, test.wantAcked)
+			}
+		})
+	}
+}
+
+func TestListenToMessagesNacksDropped(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	harness.Bot.Handlers.SetQueueCapacity(1)
+	harness.Bot.Handlers.SetQueueOverflowPolicy(sandwich.OverflowPolicyDropNewest)
+
+	started := make(chan struct{}, 4)
+	release := make(chan struct{})
+
+	sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(*sandwich.EventContext) error {
+		started <- struct{}{}
+		<-release
+
+		return nil
+	})
+
+	messages := listenToMessages(t, harness, sandwich.WithMaxInFlight(0))
+	settled := make(chan bool, 4)
+
+	send := func() {
+		messages <- newMQMessage(sandwichtest.Marshal(sandwichtest.Resumed(discord.Resume{})), settled)
+	}
+
+	// The first event is being handled and the second has been taken from the queue,
+	// waiting for the worker, so the third fills the queue and the fourth is dropped.
+	send()
+	<-started
+
+	send()
+
+	// Messages are dispatched one after another, so the second event has been queued once
+	// the next message is received.
+	messages <- newMQMessage([]byte("not json"), make(chan bool, 1))
+
+	for queuedEvents(harness.Bot.Handlers) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	send()
+	send()
+
+	if acked := waitSettled(t, settled); acked {
+		t.Error("dropped message was acked, want nacked")
+	}
+
+	close(release)
+
+	for range 3 {
+		if acked := waitSettled(t, settled); !acked {
+			t.Error("handled message was nacked, want acked")
+		}
+	}
+}
+
+func TestListenToMessagesHandlerConcurrency(t *testing.T) {
+	harness := sandwichtest.New(t)
+	harness.Bot.Handlers.SetHandlerConcurrency(2)
+
+	started := make(chan struct{}, 2)
+
+	// Each listener waits for the other to start, so they only succeed if they run concurrently.
+	for range 2 {
+		sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(*sandwich.EventContext) error {
+			started <- struct{}{}
+
+			deadline := time.After(time.Second * 5)
+
+			for len(started) < 2 {
+				select {
+				case <-deadline:
+					return errTest
+				case <-time.After(time.Millisecond):
+				}
+			}
+
+			return nil
+		})
+	}
+
+	settled := make(chan bool, 1)
+
+	listenToMessages(t, harness) <- newMQMessage(sandwichtest.Marshal(sandwichtest.Resumed(discord.Resume{})), settled)
+
+	if acked := waitSettled(t, settled); !acked {
+		t.Error("message was nacked, want acked")
+	}
+}
+
+func TestWithMaxInFlight(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	release := make(chan struct{})
+
+	sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(*sandwich.EventContext) error {
+		<-release
+
+		return nil
+	})
+
+	messages := listenToMessages(t, harness, sandwich.WithMaxInFlight(1))
+	settled := make(chan bool, 2)
+
+	message := func() *sandwich.MQMessage {
+		return newMQMessage(sandwichtest.Marshal(sandwichtest.Resumed(discord.Resume{})), settled)
+	}
+
+	messages <- message()
+
+	select {
+	case messages <- message():
+		t.Fatal("received a message over the limit")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	close(release)
+
+	if acked := waitSettled(t, settled); !acked {
+		t.Error("message was nacked, want acked")
+	}
+
+	select {
+	case messages <- message():
+	case <-time.After(time.Second * 5):
+		t.Fatal("message was not received once the first was acked")
+	}
+
+	if acked := waitSettled(t, settled); !acked {
+		t.Error("message was nacked, want acked")
+	}
+}
+
+// errAckFailed is returned by the Ack of messages in TestListenToMessagesAckError.
+var errAckFailed = errors.New("ack failed")
+
+func TestListenToMessagesAckError(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	acked := make(chan struct{})
+
+	messages := listenToMessages(t, harness, sandwich.WithMaxInFlight(1))
+
+	for range 2 {
+		messages <- &sandwich.MQMessage{
+			Data: sandwichtest.Marshal(sandwichtest.Resumed(discord.Resume{})),
+			Ack: func(context.Context) error {
+				acked <- struct{}{}
+
+				return errAckFailed
+			},
+		}
+
+		// A failed ack still releases the message, so the next one is received.
+		select {
+		case <-acked:
+		case <-time.After(time.Second * 5):
+			t.Fatal("message was not acked")
+		}
+	}
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"fmt"
 	"log/slog"
+	"sync"
 )
 
 type Bot struct {
@@ -38,6 +39,9 @@ func (bot *Bot) RegisterCog(cog Cog) error {
 		return ErrCogAlreadyRegistered
 	}
 
+	// Listeners the cog registers on the bot directly are marked as registered by the cog.
+	existing := registeredListeners(bot.Handlers)
+
 	if err := cog.RegisterCog(bot); err != nil {
 		bot.Logger.Error("Failed to register cog", "cog", cogInfo.Name, "error", err)
 		panic(fmt.Sprintf(`sandwich: RegisterCog(%v): %v`, cog, err.Error()))
@@ -53,15 +57,77 @@ func (bot *Bot) RegisterCog(cog Cog) error {
 		cast.BotLoad(bot)
 	}
 
+	setListenersCog(bot.Handlers, cogInfo.Name, existing)
+
 	if cast, ok := cog.(CogWithEvents); ok {
 		bot.Logger.Info("Cog has events", "cog", cogInfo.Name)
 
-		bot.RegisterCogEvents(cast.GetEventHandlers())
+		events := cast.GetEventHandlers()
+		setListenersCog(events, cogInfo.Name, nil)
+
+		bot.RegisterCogEvents(events)
 	}
 
 	return nil
 }
 
+// UnloadCogs calls BotUnload on every cog that implements it.
+func (bot *Bot) UnloadCogs(wg *sync.WaitGroup) {
+	for name, cog := range bot.Cogs {
+		if cast, ok := cog.(CogWithBotUnload); ok {
+			bot.Logger.Info("Unloading cog", "cog", name)
+
+			cast.BotUnload(bot, wg)
+		}
+	}
+}
+
+// registeredListeners returns every listener registered to the handlers. Events appended
+// to EventHandler.Events directly are replaced by their EventListener so they can be compared.
+func registeredListeners(events *Handlers) map[*EventListener]bool {
+	events.eventHandlersMu.RLock()
+	defer events.eventHandlersMu.RUnlock()
+
+	listeners := make(map[*EventListener]bool)
+
+	for _, eventHandler := range events.EventHandlers {
+		eventHandler.EventsMu.Lock()
+
+		for i, event := range eventHandler.Events {
+			listener := asEventListener(event)
+			listeners[listener] = true
+
+			eventHandler.Events[i] = listener
+		}
+
+		eventHandler.EventsMu.Unlock()
+	}
+
+	return listeners
+}
+
+// setListenersCog marks every listener without a cog as registered by the cog, apart from
+// the listeners in existing.
+func setListenersCog(events *Handlers, cogName string, existing map[*EventListener]bool) {
+	events.eventHandlersMu.RLock()
+	defer events.eventHandlersMu.RUnlock()
+
+	for _, eventHandler := range events.EventHandlers {
+		eventHandler.EventsMu.Lock()
+
+		for i, event := range eventHandler.Events {
+			listener := asEventListener(event)
+			if listener.Cog == "" && !existing[listener] {
+				listener.Cog = cogName
+			}
+
+			eventHandler.Events[i] = listener
+		}
+
+		eventHandler.EventsMu.Unlock()
+	}
+}
+
 func (bot *Bot) RegisterCogEvents(events *Handlers) {
 	events.eventHandlersMu.RLock()
 	defer events.eventHandlersMu.RUnlock()
@@ -0,0 +1,73 @@
+package internal_test
+
+import (
+	"testing"
+
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+)
+
+// testCog registers a listener on the bot directly and, if events is set, another from
+// its own handlers.
+type testCog struct {
+	name   string
+	events *sandwich.Handlers
+
+	listener *sandwich.EventListener
+}
+
+func (cog *testCog) CogInfo() *sandwich.CogInfo {
+	return &sandwich.CogInfo{Name: cog.name}
+}
+
+func (cog *testCog) RegisterCog(bot *sandwich.Bot) error {
+	cog.listener = sandwich.On(bot.Handlers, sandwich.EventResumed, func(*sandwich.EventContext) error {
+		return nil
+	})
+
+	return nil
+}
+
+type testCogWithEvents struct {
+	testCog
+}
+
+func (cog *testCogWithEvents) GetEventHandlers() *sandwich.Handlers {
+	return cog.events
+}
+
+func TestRegisterCogSetsListenerCog(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	botListener := sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(*sandwich.EventContext) error {
+		return nil
+	})
+
+	events := sandwich.SetupHandler(nil)
+	eventsListener := sandwich.On(events, sandwich.EventResumed, func(*sandwich.EventContext) error {
+		return nil
+	})
+
+	direct := &testCog{name: "direct"}
+	withEvents := &testCogWithEvents{testCog{name: "events", events: events}}
+
+	harness.Bot.MustRegisterCog(direct)
+	harness.Bot.MustRegisterCog(withEvents)
+
+	tests := []struct {
+		name     string
+		listener *sandwich.EventListener
+		want     string
+	}{
+		{name: "bot", listener: botListener, want: ""},
+		{name: "direct", listener: direct.listener, want: "direct"},
+		{name: "events direct", listener: withEvents.listener, want: "events"},
+		{name: "events handlers", listener: eventsListener, want: "events"},
+	}
+
+	for _, test := range tests {
+		if test.listener.Cog != test.want {
+			t.Errorf("%s: listener has cog %q, want %q", test.name, test.listener.Cog, test.want)
+		}
+	}
+}
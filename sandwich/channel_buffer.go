@@ -1,18 +1,101 @@
 package internal
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
+
+// OverflowPolicy controls what a bounded ChannelBuffer does when an item
+// is pushed whilst the buffer is at capacity.
+type OverflowPolicy uint8
+
+const (
+	// OverflowPolicyBlock blocks Push until the buffer has space.
+	OverflowPolicyBlock OverflowPolicy = iota
+	// OverflowPolicyDropNewest discards the item being pushed.
+	OverflowPolicyDropNewest
+	// OverflowPolicyDropOldest discards the oldest item in the buffer.
+	OverflowPolicyDropOldest
+	// OverflowPolicyDropPriority discards the oldest item with the lowest priority.
+	// If the item being pushed has a lower priority than everything buffered, it is discarded instead.
+	OverflowPolicyDropPriority
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowPolicyBlock:
+		return "block"
+	case OverflowPolicyDropNewest:
+		return "drop_newest"
+	case OverflowPolicyDropOldest:
+		return "drop_oldest"
+	case OverflowPolicyDropPriority:
+		return "drop_priority"
+	default:
+		return "unknown"
+	}
+}
 
 type ChannelBuffer[T any] struct {
-	Out    chan T
-	buffer []T
-	cond   *sync.Cond
+	Out     chan T
+	buffer  []T
+	cond    *sync.Cond
+	notFull *sync.Cond
+
+	capacity int
+	policy   OverflowPolicy
+	priority func(T) int
+	onDrop   func(T)
+
+	closed bool
+
+	// sending is true whilst an item taken from the buffer is waiting to be received from Out.
+	sending bool
+
+	pushed     uint64
+	dropped    uint64
+	lastPushed time.Time
+}
+
+// ChannelBufferStats is a snapshot of the state of a ChannelBuffer.
+type ChannelBufferStats struct {
+	Len int
+	Cap int
+
+	// Pushed is the number of items that have been added to the buffer.
+	Pushed uint64
+	// Dropped is the number of items discarded by the overflow policy.
+	Dropped uint64
+
+	LastPushed time.Time
+	Closed     bool
 }
 
+// NewChannelBuffer creates an unbounded ChannelBuffer.
 func NewChannelBuffer[T any]() *ChannelBuffer[T] {
+	return NewBoundedChannelBuffer[T](0, OverflowPolicyBlock, nil, nil)
+}
+
+// NewBoundedChannelBuffer creates a ChannelBuffer holding at most capacity items.
+// A capacity of 0 or less leaves the buffer unbounded. priority is only used by
+// OverflowPolicyDropPriority and onDrop, if set, is called for every discarded item.
+func NewBoundedChannelBuffer[T any](capacity int, policy OverflowPolicy, priority func(T) int, onDrop func(T)) *ChannelBuffer[T] {
+	mu := &sync.Mutex{}
+
 	channelBuffer := &ChannelBuffer[T]{
-		Out:    make(chan T),
-		buffer: make([]T, 0),
-		cond:   sync.NewCond(&sync.Mutex{}),
+		Out:     make(chan T),
+		buffer:  make([]T, 0),
+		cond:    sync.NewCond(mu),
+		notFull: sync.NewCond(mu),
+
+		capacity: capacity,
+		policy:   policy,
+		priority: priority,
+		onDrop:   onDrop,
+	}
+
+	if channelBuffer.priority == nil {
+		channelBuffer.priority = func(T) int { return 0 }
 	}
 
 	go channelBuffer.run()
@@ -20,11 +103,64 @@ func NewChannelBuffer[T any]() *ChannelBuffer[T] {
 	return channelBuffer
 }
 
-func (cb *ChannelBuffer[T]) Push(item T) {
+// Push adds an item to the buffer, applying the overflow policy if the buffer is full.
+// Returns false if the pushed item was discarded or the buffer is closed.
+func (cb *ChannelBuffer[T]) Push(item T) bool {
+	var (
+		dropped    T
+		hasDropped bool
+		accepted   = true
+	)
+
 	cb.cond.L.Lock()
-	cb.buffer = append(cb.buffer, item)
-	cb.cond.Signal() // wake a waiter
+
+	if cb.capacity > 0 && len(cb.buffer) >= cb.capacity && !cb.closed {
+		switch cb.policy {
+		case OverflowPolicyBlock:
+			for len(cb.buffer) >= cb.capacity && !cb.closed {
+				cb.notFull.Wait() // sleep until run makes space
+			}
+		case OverflowPolicyDropNewest:
+			dropped, hasDropped, accepted = item, true, false
+		case OverflowPolicyDropOldest:
+			dropped, hasDropped = cb.buffer[0], true
+			cb.buffer = cb.buffer[1:]
+		case OverflowPolicyDropPriority:
+			index := cb.lowestPriorityIndex()
+
+			if cb.priority(item) < cb.priority(cb.buffer[index]) {
+				dropped, hasDropped, accepted = item, true, false
+			} else {
+				dropped, hasDropped = cb.buffer[index], true
+				cb.buffer = append(cb.buffer[:index], cb.buffer[index+1:]...)
+			}
+		}
+	}
+
+	if cb.closed {
+		cb.cond.L.Unlock()
+
+		return false
+	}
+
+	if accepted {
+		cb.buffer = append(cb.buffer, item)
+		cb.pushed++
+		cb.lastPushed = time.Now()
+		cb.cond.Signal() // wake a waiter
+	}
+
+	if hasDropped {
+		cb.dropped++
+	}
+
 	cb.cond.L.Unlock()
+
+	if hasDropped && cb.onDrop != nil {
+		cb.onDrop(dropped)
+	}
+
+	return accepted
 }
 
 func (cb *ChannelBuffer[T]) Len() int {
@@ -35,17 +171,123 @@ func (cb *ChannelBuffer[T]) Len() int {
 	return length
 }
 
+// Cap returns the capacity of the buffer. A capacity of 0 means the buffer is unbounded.
+func (cb *ChannelBuffer[T]) Cap() int {
+	return cb.capacity
+}
+
+// Policy returns what the buffer does when an item is pushed whilst it is at capacity.
+func (cb *ChannelBuffer[T]) Policy() OverflowPolicy {
+	return cb.policy
+}
+
+// Stats returns a snapshot of the state of the buffer.
+func (cb *ChannelBuffer[T]) Stats() ChannelBufferStats {
+	cb.cond.L.Lock()
+	defer cb.cond.L.Unlock()
+
+	return ChannelBufferStats{
+		Len:        len(cb.buffer),
+		Cap:        cb.capacity,
+		Pushed:     cb.pushed,
+		Dropped:    cb.dropped,
+		LastPushed: cb.lastPushed,
+		Closed:     cb.closed,
+	}
+}
+
+// Closed returns true if the buffer no longer accepts new items.
+func (cb *ChannelBuffer[T]) Closed() bool {
+	cb.cond.L.Lock()
+	closed := cb.closed
+	cb.cond.L.Unlock()
+
+	return closed
+}
+
+// Close stops the buffer from accepting new items. Items already in the buffer
+// are still sent to Out, which is closed once the buffer is empty.
+func (cb *ChannelBuffer[T]) Close() {
+	cb.cond.L.Lock()
+	cb.closed = true
+	cb.cond.Broadcast()
+	cb.notFull.Broadcast()
+	cb.cond.L.Unlock()
+}
+
+// CloseIfEmpty closes the buffer if it is empty and no item is waiting to be received
+// from Out. Returns true if the buffer was closed.
+func (cb *ChannelBuffer[T]) CloseIfEmpty() bool {
+	cb.cond.L.Lock()
+	defer cb.cond.L.Unlock()
+
+	if len(cb.buffer) > 0 || cb.sending {
+		return false
+	}
+
+	cb.closed = true
+	cb.cond.Broadcast()
+	cb.notFull.Broadcast()
+
+	return true
+}
+
+// Discard removes every item in the buffer and returns how many were removed.
+func (cb *ChannelBuffer[T]) Discard() int {
+	return len(cb.Drain())
+}
+
+// Drain removes every item in the buffer and returns them.
+func (cb *ChannelBuffer[T]) Drain() []T {
+	cb.cond.L.Lock()
+	drained := cb.buffer
+	cb.buffer = make([]T, 0)
+	cb.cond.Broadcast()
+	cb.notFull.Broadcast()
+	cb.cond.L.Unlock()
+
+	return drained
+}
+
+// lowestPriorityIndex returns the index of the oldest item with the lowest priority.
+// The caller must hold the lock and the buffer must not be empty.
+func (cb *ChannelBuffer[T]) lowestPriorityIndex() int {
+	index := 0
+	lowest := cb.priority(cb.buffer[0])
+
+	for i := 1; i < len(cb.buffer); i++ {
+		if priority := cb.priority(cb.buffer[i]); priority < lowest {
+			index, lowest = i, priority
+		}
+	}
+
+	return index
+}
+
 func (cb *ChannelBuffer[T]) run() {
 	for {
 		cb.cond.L.Lock()
-		for len(cb.buffer) == 0 {
+		for len(cb.buffer) == 0 && !cb.closed {
 			cb.cond.Wait() // sleep until Push signals
 		}
 
+		if len(cb.buffer) == 0 {
+			cb.cond.L.Unlock()
+			close(cb.Out)
+
+			return
+		}
+
 		item := cb.buffer[0]
 		cb.buffer = cb.buffer[1:]
+		cb.sending = true
+		cb.notFull.Signal() // wake a blocked Push
 		cb.cond.L.Unlock()
 
 		cb.Out <- item
+
+		cb.cond.L.Lock()
+		cb.sending = false
+		cb.cond.L.Unlock()
 	}
 }
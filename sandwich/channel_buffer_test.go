@@ -0,0 +1,258 @@
+package internal_test
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+// fillChannelBuffer pushes items to a buffer whose run goroutine is holding blocker, so
+// the buffer holds every pushed item until Out is received from.
+func fillChannelBuffer(t *testing.T, channelBuffer *sandwich.ChannelBuffer[int], blocker int, items ...int) {
+	t.Helper()
+
+	channelBuffer.Push(blocker)
+
+	for channelBuffer.Len() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	for _, item := range items {
+		if !channelBuffer.Push(item) {
+			t.Fatalf("failed to push %d to a buffer with space", item)
+		}
+	}
+}
+
+// receiveChannelBuffer returns the next count items received from a buffer.
+func receiveChannelBuffer(t *testing.T, channelBuffer *sandwich.ChannelBuffer[int], count int) []int {
+	t.Helper()
+
+	received := make([]int, 0, count)
+
+	for range count {
+		select {
+		case item := <-channelBuffer.Out:
+			received = append(received, item)
+		case <-time.After(time.Second * 5):
+			t.Fatalf("timed out after receiving %v", received)
+		}
+	}
+
+	return received
+}
+
+func TestChannelBufferOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       sandwich.OverflowPolicy
+		buffered     []int
+		push         int
+		wantAccepted bool
+		wantDropped  []int
+		wantReceived []int
+	}{
+		{
+			name:         "drop newest",
+			policy:       sandwich.OverflowPolicyDropNewest,
+			buffered:     []int{1, 2},
+			push:         3,
+			wantAccepted: false,
+			wantDropped:  []int{3},
+			wantReceived: []int{0, 1, 2},
+		},
+		{
+			name:         "drop oldest",
+			policy:       sandwich.OverflowPolicyDropOldest,
+			buffered:     []int{1, 2},
+			push:         3,
+			wantAccepted: true,
+			wantDropped:  []int{1},
+			wantReceived: []int{0, 2, 3},
+		},
+		{
+			name:         "drop priority",
+			policy:       sandwich.OverflowPolicyDropPriority,
+			buffered:     []int{5, 1},
+			push:         3,
+			wantAccepted: true,
+			wantDropped:  []int{1},
+			wantReceived: []int{0, 5, 3},
+		},
+		{
+			name:         "drop priority oldest of equal priority",
+			policy:       sandwich.OverflowPolicyDropPriority,
+			buffered:     []int{2, 2},
+			push:         2,
+			wantAccepted: true,
+			wantDropped:  []int{2},
+			wantReceived: []int{0, 2, 2},
+		},
+		{
+			name:         "drop priority lower than buffered",
+			policy:       sandwich.OverflowPolicyDropPriority,
+			buffered:     []int{5, 4},
+			push:         1,
+			wantAccepted: false,
+			wantDropped:  []int{1},
+			wantReceived: []int{0, 5, 4},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				dropped []int
+			)
+
+			// Items are their own priority.
+			channelBuffer := sandwich.NewBoundedChannelBuffer(len(test.buffered), test.policy,
+				func(item int) int { return item },
+				func(item int) {
+					mu.Lock()
+					dropped = append(dropped, item)
+					mu.Unlock()
+				})
+
+			fillChannelBuffer(t, channelBuffer, 0, test.buffered...)
+
+			if accepted := channelBuffer.Push(test.push); accepted != test.wantAccepted {
+				t.Errorf("Push(%d) = %v, want %v", test.push, accepted, test.wantAccepted)
+			}
+
+			if stats := channelBuffer.Stats(); stats.Dropped != uint64(len(test.wantDropped)) {
+				t.Errorf("stats has %d dropped items, want %d", stats.Dropped, len(test.wantDropped))
+			}
+
+			mu.Lock()
+			if !slices.Equal(dropped, test.wantDropped) {
+				t.Errorf("dropped %v, want %v", dropped, test.wantDropped)
+			}
+			mu.Unlock()
+
+			if received := receiveChannelBuffer(t, channelBuffer, len(test.wantReceived)); !slices.Equal(received, test.wantReceived) {
+				t.Errorf("received %v, want %v", received, test.wantReceived)
+			}
+		})
+	}
+}
+
+func TestChannelBufferOverflowPolicyBlock(t *testing.T) {
+	channelBuffer := sandwich.NewBoundedChannelBuffer[int](1, sandwich.OverflowPolicyBlock, nil, nil)
+
+	fillChannelBuffer(t, channelBuffer, 0, 1)
+
+	pushed := make(chan bool, 1)
+
+	go func() {
+		pushed <- channelBuffer.Push(2)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("Push did not block whilst the buffer was full")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	if item := <-channelBuffer.Out; item != 0 {
+		t.Fatalf("received %d, want 0", item)
+	}
+
+	select {
+	case accepted := <-pushed:
+		if !accepted {
+			t.Fatal("Push discarded the item once the buffer had space")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Push did not return once the buffer had space")
+	}
+
+	if received := receiveChannelBuffer(t, channelBuffer, 2); !slices.Equal(received, []int{1, 2}) {
+		t.Errorf("received %v, want [1 2]", received)
+	}
+}
+
+func TestChannelBufferClose(t *testing.T) {
+	channelBuffer := sandwich.NewChannelBuffer[int]()
+
+	fillChannelBuffer(t, channelBuffer, 0, 1, 2)
+
+	channelBuffer.Close()
+
+	if channelBuffer.Push(3) {
+		t.Error("pushed to a closed buffer")
+	}
+
+	received := make([]int, 0)
+	for item := range channelBuffer.Out {
+		received = append(received, item)
+	}
+
+	if !slices.Equal(received, []int{0, 1, 2}) {
+		t.Errorf("received %v, want [0 1 2]", received)
+	}
+}
+
+func TestChannelBufferCloseUnblocksPush(t *testing.T) {
+	channelBuffer := sandwich.NewBoundedChannelBuffer[int](1, sandwich.OverflowPolicyBlock, nil, nil)
+
+	fillChannelBuffer(t, channelBuffer, 0, 1)
+
+	pushed := make(chan bool, 1)
+
+	go func() {
+		pushed <- channelBuffer.Push(2)
+	}()
+
+	channelBuffer.Close()
+
+	select {
+	case accepted := <-pushed:
+		if accepted {
+			t.Fatal("pushed to a closed buffer")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Push did not return once the buffer was closed")
+	}
+
+	if received := receiveChannelBuffer(t, channelBuffer, 2); !slices.Equal(received, []int{0, 1}) {
+		t.Errorf("received %v, want [0 1]", received)
+	}
+}
+
+// TestChannelBufferCloseIfEmpty makes sure a buffer is not closed whilst an item taken
+// from it is still waiting to be received.
+func TestChannelBufferCloseIfEmpty(t *testing.T) {
+	channelBuffer := sandwich.NewChannelBuffer[int]()
+
+	fillChannelBuffer(t, channelBuffer, 1)
+
+	if channelBuffer.CloseIfEmpty() {
+		t.Fatal("closed the buffer whilst an item was waiting to be received")
+	}
+
+	if item := <-channelBuffer.Out; item != 1 {
+		t.Fatalf("received %d, want 1", item)
+	}
+
+	deadline := time.Now().Add(time.Second * 5)
+	for !channelBuffer.CloseIfEmpty() {
+		if time.Now().After(deadline) {
+			t.Fatal("did not close the empty buffer")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if channelBuffer.Push(2) {
+		t.Fatal("pushed to a closed buffer")
+	}
+
+	if _, ok := <-channelBuffer.Out; ok {
+		t.Fatal("Out was not closed")
+	}
+}
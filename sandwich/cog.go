@@ -27,6 +27,7 @@ type CogWithBotLoad interface {
 }
 
 // CogWithBotUnload is an interface for any cog that implements methods that run when a bot unloads.
+// Cogs that unload in the background should call wg.Add before BotUnload returns and wg.Done once finished.
 type CogWithBotUnload interface {
 	BotUnload(bot *Bot, wg *sync.WaitGroup)
 }
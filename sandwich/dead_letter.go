@@ -0,0 +1,263 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+)
+
+// DeadLetterReason is why an event could not be handled.
+type DeadLetterReason string
+
+const (
+	// DeadLetterUnmarshalFailed is used when a received message is not a valid ProducedPayload.
+	DeadLetterUnmarshalFailed DeadLetterReason = "unmarshal_failed"
+	// DeadLetterParserFailed is used when the parser of an event returns an error.
+	DeadLetterParserFailed DeadLetterReason = "parser_failed"
+	// DeadLetterHandlerFailed is used when a listener that is not retried returns an error.
+	DeadLetterHandlerFailed DeadLetterReason = "handler_failed"
+	// DeadLetterRetriesExhausted is used when a listener still fails after its retries.
+	DeadLetterRetriesExhausted DeadLetterReason = "retries_exhausted"
+	// DeadLetterRetryAbandoned is used when the handlers are closed before a failed listener is retried.
+	DeadLetterRetryAbandoned DeadLetterReason = "retry_abandoned"
+	// DeadLetterQueueOverflow is used when an event is dropped from a full worker queue.
+	DeadLetterQueueOverflow DeadLetterReason = "queue_overflow"
+)
+
+// DeadLetter is an event that could not be handled, along with why.
+type DeadLetter struct {
+	Reason DeadLetterReason `json:"reason"`
+	Error  string           `json:"error,omitempty"`
+
+	Event       string `json:"event,omitempty"`
+	Application string `json:"application,omitempty"`
+
+	// Handler and Attempts are set when a listener failed.
+	Handler  string `json:"handler,omitempty"`
+	Attempts int    `json:"attempts,omitempty"`
+
+	// Payload is the payload of the event. It is nil if the message could not be unmarshalled.
+	Payload *sandwich_daemon.ProducedPayload `json:"payload,omitempty"`
+
+	// Raw is the message as received. It is only set if the message could not be unmarshalled.
+	Raw []byte `json:"raw,omitempty"`
+
+	ReceivedAt time.Time `json:"received_at,omitzero"`
+	FailedAt   time.Time `json:"failed_at"`
+}
+
+// DeadLetterSink stores events that could not be handled so they can be inspected
+// and reprocessed later. DeadLetter may be called from multiple goroutines.
+type DeadLetterSink interface {
+	DeadLetter(ctx context.Context, deadLetter *DeadLetter) error
+}
+
+// SetDeadLetterSink sets where events that could not be handled are sent. A nil sink
+// discards them.
+func (sandwich *Sandwich) SetDeadLetterSink(sink DeadLetterSink) {
+	sandwich.DeadLetterSink = sink
+}
+
+// deadLetter sends an event to the dead-letter sink, if there is one.
+func (sandwich *Sandwich) deadLetter(ctx context.Context, deadLetter *DeadLetter) {
+	if sandwich == nil || sandwich.DeadLetterSink == nil {
+		return
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if deadLetter.FailedAt.IsZero() {
+		deadLetter.FailedAt = time.Now()
+	}
+
+	if deadLetter.Payload != nil {
+		if deadLetter.Event == "" {
+			deadLetter.Event = deadLetter.Payload.Type
+		}
+
+		if deadLetter.Application == "" {
+			deadLetter.Application = deadLetter.Payload.Metadata.Application
+		}
+	}
+
+	err := sandwich.DeadLetterSink.DeadLetter(context.WithoutCancel(ctx), deadLetter)
+	if err != nil {
+		sandwich.Logger.Warn("Failed to write dead letter",
+			"reason", deadLetter.Reason,
+			"event", deadLetter.Event,
+			"error", err)
+	}
+}
+
+// newDeadLetter creates a DeadLetter for the event of an event context.
+func newDeadLetter(eventCtx *EventContext, reason DeadLetterReason, err error) *DeadLetter {
+	deadLetter := &DeadLetter{
+		Reason:     reason,
+		Payload:    eventCtx.Payload,
+		ReceivedAt: eventCtx.receivedAt,
+		FailedAt:   time.Now(),
+	}
+
+	if err != nil {
+		deadLetter.Error = err.Error()
+	}
+
+	return deadLetter
+}
+
+// FileDeadLetterSink writes dead letters to a file as JSON lines. Once the file reaches
+// its maximum size it is rotated, keeping a limited number of old files named path.1,
+// path.2 and so on, with path.1 being the newest.
+type FileDeadLetterSink struct {
+	mu sync.Mutex
+
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	// file is nil if it could not be reopened after being rotated, in which case it is
+	// opened again by the next DeadLetter.
+	file   *os.File
+	size   int64
+	closed bool
+}
+
+// NewFileDeadLetterSink opens a file to append dead letters to. A maxSize of 0 or less
+// never rotates the file and maxFiles is the number of rotated files to keep.
+func NewFileDeadLetterSink(path string, maxSize int64, maxFiles int) (*FileDeadLetterSink, error) {
+	sink := &FileDeadLetterSink{
+		mu:       sync.Mutex{},
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+
+	err := sink.open()
+	if err != nil {
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+func (sink *FileDeadLetterSink) DeadLetter(_ context.Context, deadLetter *DeadLetter) error {
+	line, err := json.Marshal(deadLetter)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	line = append(line, '\n')
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	if sink.closed {
+		return os.ErrClosed
+	}
+
+	if sink.file == nil {
+		err = sink.open()
+		if err != nil {
+			return err
+		}
+	}
+
+	if sink.maxSize > 0 && sink.size > 0 && sink.size+int64(len(line)) > sink.maxSize {
+		err = sink.rotate()
+		if err != nil {
+			return err
+		}
+	}
+
+	written, err := sink.file.Write(line)
+	sink.size += int64(written)
+
+	if err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// Close closes the file. Dead letters written after Close return os.ErrClosed.
+func (sink *FileDeadLetterSink) Close() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	sink.closed = true
+
+	if sink.file == nil {
+		return nil
+	}
+
+	err := sink.file.Close()
+	sink.file = nil
+
+	return err
+}
+
+func (sink *FileDeadLetterSink) open() error {
+	file, err := os.OpenFile(sink.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead letter file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return fmt.Errorf("failed to stat dead letter file: %w", err)
+	}
+
+	sink.file = file
+	sink.size = info.Size()
+
+	return nil
+}
+
+// rotate moves the current file to path.1, shifting older files along and removing
+// the oldest. The caller must hold the lock.
+func (sink *FileDeadLetterSink) rotate() error {
+	err := sink.file.Close()
+	sink.file = nil
+
+	if err != nil {
+		return fmt.Errorf("failed to close dead letter file: %w", err)
+	}
+
+	if sink.maxFiles > 0 {
+		err = os.Remove(rotatedPath(sink.path, sink.maxFiles))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove dead letter file: %w", err)
+		}
+
+		for i := sink.maxFiles - 1; i >= 1; i-- {
+			err = os.Rename(rotatedPath(sink.path, i), rotatedPath(sink.path, i+1))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to rotate dead letter file: %w", err)
+			}
+		}
+
+		err = os.Rename(sink.path, rotatedPath(sink.path, 1))
+	} else {
+		err = os.Remove(sink.path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to rotate dead letter file: %w", err)
+	}
+
+	return sink.open()
+}
+
+func rotatedPath(path string, index int) string {
+	return fmt.Sprintf("%s.%d", path, index)
+}
@@ -0,0 +1,236 @@
+package internal_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+// memoryDeadLetterSink keeps the dead letters it receives.
+type memoryDeadLetterSink struct {
+	mu          sync.Mutex
+	deadLetters []*sandwich.DeadLetter
+}
+
+func (sink *memoryDeadLetterSink) DeadLetter(_ context.Context, deadLetter *sandwich.DeadLetter) error {
+	sink.mu.Lock()
+	sink.deadLetters = append(sink.deadLetters, deadLetter)
+	sink.mu.Unlock()
+
+	return nil
+}
+
+func (sink *memoryDeadLetterSink) reasons() []sandwich.DeadLetterReason {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	reasons := make([]sandwich.DeadLetterReason, len(sink.deadLetters))
+	for i, deadLetter := range sink.deadLetters {
+		reasons[i] = deadLetter.Reason
+	}
+
+	return reasons
+}
+
+// wait returns the dead letters received once there are count of them.
+func (sink *memoryDeadLetterSink) wait(t *testing.T, count int) []*sandwich.DeadLetter {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second * 5)
+
+	for {
+		sink.mu.Lock()
+		deadLetters := sink.deadLetters
+		sink.mu.Unlock()
+
+		if len(deadLetters) >= count {
+			return deadLetters
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d dead letters, want %d", len(deadLetters), count)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDeadLetterReasons(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   *sandwich.RetryPolicy
+		payload  sandwich_daemon.ProducedPayload
+		want     sandwich.DeadLetterReason
+		attempts int
+	}{
+		{
+			name:     "handler failed",
+			payload:  newPayload(discord.DiscordEventResumed, discord.Resume{}),
+			want:     sandwich.DeadLetterHandlerFailed,
+			attempts: 1,
+		},
+		{
+			name:     "retries exhausted",
+			policy:   sandwich.NewRetryPolicy(2, time.Millisecond, 0),
+			payload:  newPayload(discord.DiscordEventResumed, discord.Resume{}),
+			want:     sandwich.DeadLetterRetriesExhausted,
+			attempts: 2,
+		},
+		{
+			name:    "parser failed",
+			payload: newPayload(discord.DiscordEventResumed, json.RawMessage(`[]`)),
+			want:    sandwich.DeadLetterParserFailed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sandwichClient, bot := newTestBot()
+
+			sink := &memoryDeadLetterSink{}
+			sandwichClient.SetDeadLetterSink(sink)
+
+			bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+				return errTest
+			}).SetRetryPolicy(test.policy)
+
+			dispatch(t, sandwichClient, test.payload)
+
+			deadLetter := sink.wait(t, 1)[0]
+			if deadLetter.Reason != test.want || deadLetter.Attempts != test.attempts {
+				t.Errorf("dead letter has reason %s after %d attempts, want %s after %d attempts",
+					deadLetter.Reason, deadLetter.Attempts, test.want, test.attempts)
+			}
+
+			if deadLetter.Payload == nil || deadLetter.Payload.Type != discord.DiscordEventResumed {
+				t.Errorf("dead letter does not contain the payload")
+			}
+		})
+	}
+}
+
+func TestFileDeadLetterSinkRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead_letters.jsonl")
+
+	sink, err := sandwich.NewFileDeadLetterSink(path, 1, 2)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+
+	defer sink.Close()
+
+	for _, event := range []string{"A", "B", "C", "D"} {
+		err = sink.DeadLetter(t.Context(), &sandwich.DeadLetter{Event: event})
+		if err != nil {
+			t.Fatalf("failed to write dead letter: %v", err)
+		}
+	}
+
+	// Every dead letter is larger than the maximum size, so each one is in its own file.
+	for file, want := range map[string]string{path: "D", path + ".1": "C", path + ".2": "B"} {
+		got := readDeadLetters(t, file)
+		if len(got) != 1 || got[0] != want {
+			t.Errorf("%s contains %v, want [%s]", filepath.Base(file), got, want)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("kept more rotated files than maxFiles")
+	}
+}
+
+// TestFileDeadLetterSinkRecoversFromRotation makes sure a failed rotation does not stop
+// later dead letters from being written.
+func TestFileDeadLetterSinkRecoversFromRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead_letters.jsonl")
+
+	sink, err := sandwich.NewFileDeadLetterSink(path, 1, 1)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+
+	defer sink.Close()
+
+	err = sink.DeadLetter(t.Context(), &sandwich.DeadLetter{Event: "A"})
+	if err != nil {
+		t.Fatalf("failed to write dead letter: %v", err)
+	}
+
+	// The file cannot be rotated whilst a directory is in the way.
+	err = os.Mkdir(path+".1", 0o755)
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	err = os.WriteFile(filepath.Join(path+".1", "file"), nil, 0o644)
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	err = sink.DeadLetter(t.Context(), &sandwich.DeadLetter{Event: "B"})
+	if err == nil {
+		t.Fatal("rotated the file over a directory")
+	}
+
+	err = os.RemoveAll(path + ".1")
+	if err != nil {
+		t.Fatalf("failed to remove directory: %v", err)
+	}
+
+	err = sink.DeadLetter(t.Context(), &sandwich.DeadLetter{Event: "C"})
+	if err != nil {
+		t.Fatalf("failed to write dead letter after rotation failed: %v", err)
+	}
+
+	if got := readDeadLetters(t, path); len(got) != 1 || got[0] != "C" {
+		t.Errorf("file contains %v, want [C]", got)
+	}
+
+	err = sink.Close()
+	if err != nil {
+		t.Fatalf("failed to close sink: %v", err)
+	}
+
+	err = sink.DeadLetter(t.Context(), &sandwich.DeadLetter{Event: "D"})
+	if !errors.Is(err, os.ErrClosed) {
+		t.Errorf("DeadLetter after Close returned %v, want %v", err, os.ErrClosed)
+	}
+}
+
+// readDeadLetters returns the events of the dead letters in a file.
+func readDeadLetters(t *testing.T, path string) []string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open dead letters: %v", err)
+	}
+
+	defer file.Close()
+
+	var events []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var deadLetter sandwich.DeadLetter
+
+		err = json.Unmarshal(scanner.Bytes(), &deadLetter)
+		if err != nil {
+			t.Fatalf("failed to unmarshal dead letter: %v", err)
+		}
+
+		events = append(events, deadLetter.Event)
+	}
+
+	return events
+}
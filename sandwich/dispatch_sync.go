@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"context"
+	"errors"
+
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+)
+
+// DispatchResult is the outcome of a synchronous dispatch.
+type DispatchResult struct {
+	// Event is the type of the dispatched payload.
+	Event string
+
+	// Ignored is true if the payload was not dispatched as no bot is registered for its identifier.
+	Ignored bool
+
+	// Dropped is true if the payload was dropped from a full worker queue, or discarded
+	// when the handlers were closed, before it was handled.
+	Dropped bool
+
+	// Errors contains the error of the parser, if it failed, followed by the error of every
+	// listener that failed after its retries, in the order they were registered.
+	// Recovered panics are included as a *HandlerError wrapping a *PanicError.
+	Errors []*HandlerError
+}
+
+// Err returns the errors of the dispatch joined together, or nil if every listener succeeded.
+func (result *DispatchResult) Err() error {
+	if result == nil || len(result.Errors) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(result.Errors))
+	for i, handlerErr := range result.Errors {
+		errs[i] = handlerErr
+	}
+
+	return errors.Join(errs...)
+}
+
+// Panics returns the panics recovered during the dispatch.
+func (result *DispatchResult) Panics() []*PanicError {
+	if result == nil {
+		return nil
+	}
+
+	panics := make([]*PanicError, 0)
+
+	for _, handlerErr := range result.Errors {
+		var panicErr *PanicError
+		if errors.As(handlerErr, &panicErr) {
+			panics = append(panics, panicErr)
+		}
+	}
+
+	return panics
+}
+
+// DispatchSync dispatches a payload on the current goroutine, bypassing the worker queues.
+// The identifier is looked up, then the parser, every listener and the ERROR handlers
+// run one after another before it returns. Listeners with a retry policy are retried
+// on the current goroutine, so DispatchSync waits for their backoff.
+func (h *Handlers) DispatchSync(eventCtx *EventContext, payload sandwich_daemon.ProducedPayload) *DispatchResult {
+	eventCtx.dispatchErrors = &dispatchErrors{}
+	eventCtx.synchronous = true
+
+	h.dispatchEvent(eventCtx, payload)
+
+	return eventCtx.dispatchResult(payload.Type)
+}
+
+// dispatchResult returns the result of a synchronous or acknowledged dispatch once it has finished.
+func (eventCtx *EventContext) dispatchResult(eventName string) *DispatchResult {
+	eventCtx.dispatchErrors.mu.Lock()
+	defer eventCtx.dispatchErrors.mu.Unlock()
+
+	return &DispatchResult{
+		Event:  eventName,
+		Errors: eventCtx.dispatchErrors.errors,
+	}
+}
+
+// DispatchProducedPayloadSync dispatches a payload to the bot of its identifier on the
+// current goroutine and returns once it has been handled. If no bot is registered, the
+// result is marked as ignored, or ErrInvalidIdentifier is returned if ErrorOnInvalidIdentifier is set.
+func (sandwich *Sandwich) DispatchProducedPayloadSync(ctx context.Context, payload sandwich_daemon.ProducedPayload) (*DispatchResult, error) {
+	bot, err := sandwich.payloadBot(payload)
+	if bot == nil {
+		if err != nil {
+			return nil, err
+		}
+
+		return &DispatchResult{Event: payload.Type, Ignored: true}, nil
+	}
+
+	return bot.DispatchSync(sandwich.newEventContext(ctx, bot.Handlers, &payload), payload), nil
+}
+
+// DispatchGRPCPayloadSync dispatches a payload received from gRPC on the current goroutine
+// and returns once it has been handled. Sandwich events are sent to the SandwichEvents and
+// every other event is sent to the bot of its identifier, the same as DispatchProducedPayloadSync.
+func (sandwich *Sandwich) DispatchGRPCPayloadSync(ctx context.Context, payload sandwich_daemon.ProducedPayload) (*DispatchResult, error) {
+	if !sandwich.SandwichEvents.hasEventHandler(payload.Type) {
+		return sandwich.DispatchProducedPayloadSync(ctx, payload)
+	}
+
+	return sandwich.SandwichEvents.DispatchSync(sandwich.newEventContext(ctx, sandwich.SandwichEvents, &payload), payload), nil
+}
@@ -0,0 +1,146 @@
+package internal_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	discord "github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+)
+
+var (
+	errOther = errors.New("other error")
+
+	// errPanic makes a listener in TestDispatchSync panic instead of returning an error.
+	errPanic = errors.New("panic")
+)
+
+func TestDispatchSync(t *testing.T) {
+	tests := []struct {
+		name        string
+		listeners   []error
+		wantIndexes []int
+		wantPanics  int
+	}{
+		{name: "no listeners", wantIndexes: []int{}},
+		{name: "succeeded", listeners: []error{nil, nil}, wantIndexes: []int{}},
+		{name: "failed in order", listeners: []error{errTest, nil, errOther}, wantIndexes: []int{0, 2}},
+		{name: "panicked", listeners: []error{nil, errPanic}, wantIndexes: []int{1}, wantPanics: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			harness := sandwichtest.New(t)
+
+			for _, listenerErr := range test.listeners {
+				sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(*sandwich.EventContext) error {
+					if listenerErr == errPanic {
+						panic("boom")
+					}
+
+					return listenerErr
+				})
+			}
+
+			result, err := harness.DispatchSync(sandwichtest.Resumed(discord.Resume{}))
+			if err != nil {
+				t.Fatalf("failed to dispatch: %v", err)
+			}
+
+			if result.Event != discord.DiscordEventResumed || result.Ignored {
+				t.Errorf("dispatch returned %+v, want a handled %s event", result, discord.DiscordEventResumed)
+			}
+
+			indexes := make([]int, 0, len(result.Errors))
+			for _, handlerErr := range result.Errors {
+				indexes = append(indexes, handlerErr.HandlerIndex)
+
+				if listenerErr := test.listeners[handlerErr.HandlerIndex]; listenerErr != errPanic && !errors.Is(handlerErr, listenerErr) {
+					t.Errorf("listener %d returned %v, want %v", handlerErr.HandlerIndex, handlerErr, listenerErr)
+				}
+			}
+
+			if !slices.Equal(indexes, test.wantIndexes) {
+				t.Errorf("listeners %v failed, want %v", indexes, test.wantIndexes)
+			}
+
+			if (result.Err() == nil) != (len(test.wantIndexes) == 0) {
+				t.Errorf("Err() = %v with %d errors", result.Err(), len(result.Errors))
+			}
+
+			if panics := result.Panics(); len(panics) != test.wantPanics {
+				t.Errorf("dispatch recovered %d panics, want %d", len(panics), test.wantPanics)
+			}
+		})
+	}
+}
+
+func TestDispatchSyncInvalidIdentifier(t *testing.T) {
+	tests := []struct {
+		name        string
+		errorOn     bool
+		wantIgnored bool
+		wantErr     error
+	}{
+		{name: "ignored", errorOn: false, wantIgnored: true, wantErr: nil},
+		{name: "error", errorOn: true, wantIgnored: false, wantErr: sandwich.ErrInvalidIdentifier},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			harness := sandwichtest.New(t)
+			harness.Sandwich.SetErrorOnInvalidIdentifier(test.errorOn)
+
+			payload := sandwichtest.Resumed(discord.Resume{}, sandwichtest.WithIdentifier("unknown"))
+
+			result, err := harness.DispatchSync(payload)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("DispatchSync returned %v, want %v", err, test.wantErr)
+			}
+
+			if ignored := result != nil && result.Ignored; ignored != test.wantIgnored {
+				t.Errorf("result is ignored: %v, want %v", ignored, test.wantIgnored)
+			}
+		})
+	}
+}
+
+func TestDispatchGRPCSync(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	var sandwichEvents, botEvents int
+
+	sandwich.On(harness.Sandwich.SandwichEvents, sandwich.EventSandwichShardStatusUpdate,
+		func(*sandwich.EventContext, string, int32, sandwich_daemon.ShardStatus) error {
+			sandwichEvents++
+
+			return nil
+		})
+
+	sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(*sandwich.EventContext) error {
+		botEvents++
+
+		return nil
+	})
+
+	for _, payload := range []sandwich_daemon.ProducedPayload{
+		sandwichtest.SandwichShardStatusUpdate(sandwichtest.DefaultIdentifier, 0, sandwich_daemon.ShardStatusReady),
+		sandwichtest.Resumed(discord.Resume{}),
+	} {
+		result, err := harness.DispatchGRPCSync(payload)
+		if err != nil {
+			t.Fatalf("failed to dispatch: %v", err)
+		}
+
+		if err := result.Err(); err != nil {
+			t.Fatalf("failed to handle %s: %v", payload.Type, err)
+		}
+	}
+
+	if sandwichEvents != 1 || botEvents != 1 {
+		t.Errorf("handled %d sandwich events and %d bot events, want 1 and 1", sandwichEvents, botEvents)
+	}
+}
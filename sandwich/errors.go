@@ -1,6 +1,13 @@
 package internal
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	"github.com/pkg/errors"
+)
 
 var (
 	ErrInvalidIdentifier  = errors.New("payload does not include a valid identifier")
@@ -8,8 +15,11 @@ var (
 	ErrInvalidToken       = errors.New("invalid token was passed")
 	ErrUnknownEvent       = errors.New("event type does not have a handler")
 	ErrUnknownGRPCError   = errors.New("grpc returned unknown error")
+	ErrChannelClosed      = errors.New("message channel was closed")
+	ErrGRPCIdle           = errors.New("grpc stream did not receive a message within the idle timeout")
 
 	ErrCogAlreadyRegistered = errors.New("cog with this name already exists")
+	ErrInvalidEventFunc     = errors.New("func does not match the type of the event")
 
 	ErrFetchMissingGuild     = errors.New("object requires guild ID to fetch")
 	ErrFetchMissingSnowflake = errors.New("object requires snowflake to fetch")
@@ -24,3 +34,122 @@ var (
 	ErrEmojiNotFound      = errors.New("emoji provided was not found")
 	ErrBadWebhookArgument = errors.New("webhook url provided was not in valid format")
 )
+
+// HandlerError is passed to ERROR handlers when an event could not be parsed or
+// one of its listeners returned an error. Use errors.As to retrieve it.
+type HandlerError struct {
+	// Event is the name of the event being dispatched.
+	Event string
+
+	// Cog is the name of the cog that registered the listener, if any.
+	Cog string
+
+	// Handler is the name of the listener's func.
+	Handler string
+
+	// HandlerIndex is the position of the listener in the event's listeners.
+	// It is -1 if the error was returned by the parser of the event.
+	HandlerIndex int
+
+	// Attempts is the number of times the listener was called, including retries.
+	Attempts int
+
+	Application string
+	ShardID     int32
+	GuildID     discord.Snowflake
+
+	Trace sandwich_daemon.Trace
+
+	Err error
+}
+
+func (e *HandlerError) Error() string {
+	if e.HandlerIndex < 0 {
+		return fmt.Sprintf("failed to parse %s event: %v", e.Event, e.Err)
+	}
+
+	if e.Cog != "" {
+		return fmt.Sprintf("%s handler %d (%s) of cog %s failed: %v", e.Event, e.HandlerIndex, e.Handler, e.Cog, e.Err)
+	}
+
+	return fmt.Sprintf("%s handler %d (%s) failed: %v", e.Event, e.HandlerIndex, e.Handler, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// newHandlerError creates a HandlerError from the current state of the event context.
+// listener should be nil if the error was returned by the parser of the event.
+func newHandlerError(eventCtx *EventContext, listener *EventListener, handlerIndex int, err error) *HandlerError {
+	handlerErr := &HandlerError{
+		HandlerIndex: handlerIndex,
+		Attempts:     1,
+		Trace:        eventCtx.Trace(),
+		Err:          err,
+	}
+
+	if listener != nil {
+		handlerErr.Cog = listener.Cog
+		handlerErr.Handler = listener.Name()
+	} else {
+		handlerErr.HandlerIndex = -1
+	}
+
+	if eventCtx.EventHandler != nil {
+		handlerErr.Event = eventCtx.EventHandler.eventName
+	}
+
+	if eventCtx.Payload != nil {
+		if handlerErr.Event == "" {
+			handlerErr.Event = eventCtx.Payload.Type
+		}
+
+		handlerErr.Application = eventCtx.Payload.Metadata.Application
+		handlerErr.ShardID = eventCtx.Payload.Metadata.Shard[1]
+	}
+
+	if eventCtx.Guild != nil {
+		handlerErr.GuildID = eventCtx.Guild.ID
+	}
+
+	return handlerErr
+}
+
+// PanicError is the error created when a parser or listener panics whilst dispatching an event.
+// It is passed to ERROR handlers wrapped in a *HandlerError.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+
+	// Handler is the name of the listener's func. It is empty if the parser panicked.
+	Handler string
+}
+
+func newPanicError(errorValue any, handler string) *PanicError {
+	return &PanicError{
+		Value:   errorValue,
+		Stack:   debug.Stack(),
+		Handler: handler,
+	}
+}
+
+func (e *PanicError) Error() string {
+	if e.Handler != "" {
+		return fmt.Sprintf("panic in %s: %v", e.Handler, e.Value)
+	}
+
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the value passed to panic if it was an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
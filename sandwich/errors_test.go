@@ -0,0 +1,204 @@
+package internal_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+var errTest = errors.New("test error")
+
+// receiveHandlerError registers an ERROR handler and returns a func that waits for the next
+// error it is called with.
+func receiveHandlerError(t *testing.T, bot *sandwich.Bot) func() *sandwich.HandlerError {
+	t.Helper()
+
+	handlerErrs := make(chan error, 1)
+
+	bot.Handlers.RegisterOnError(func(_ *sandwich.EventContext, err error) error {
+		handlerErrs <- err
+
+		return nil
+	})
+
+	return func() *sandwich.HandlerError {
+		t.Helper()
+
+		select {
+		case err := <-handlerErrs:
+			var handlerErr *sandwich.HandlerError
+			if !errors.As(err, &handlerErr) {
+				t.Fatalf("ERROR handler received %T, want *sandwich.HandlerError", err)
+			}
+
+			return handlerErr
+		case <-time.After(time.Second * 5):
+			t.Fatal("ERROR handler was not called")
+		}
+
+		return nil
+	}
+}
+
+func TestHandlerErrorProvenance(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	nextHandlerError := receiveHandlerError(t, bot)
+
+	bot.Handlers.RegisterOnMessageCreateEvent(func(*sandwich.EventContext, discord.Message) error {
+		return nil
+	})
+
+	bot.Handlers.RegisterOnMessageCreateEvent(func(*sandwich.EventContext, discord.Message) error {
+		return errTest
+	})
+
+	guildID := discord.Snowflake(10)
+
+	payload := newPayload(discord.DiscordEventMessageCreate, discord.Message{GuildID: &guildID})
+	payload.Metadata.Shard = [3]int32{0, 2, 4}
+
+	dispatch(t, sandwichClient, payload)
+
+	handlerErr := nextHandlerError()
+
+	if handlerErr.Event != discord.DiscordEventMessageCreate || handlerErr.HandlerIndex != 1 {
+		t.Errorf("error is for %s handler %d, want %s handler 1",
+			handlerErr.Event, handlerErr.HandlerIndex, discord.DiscordEventMessageCreate)
+	}
+
+	if handlerErr.Application != testApplication || handlerErr.ShardID != 2 || handlerErr.GuildID != guildID {
+		t.Errorf("error is for application %q shard %d guild %d, want application %q shard 2 guild %d",
+			handlerErr.Application, handlerErr.ShardID, handlerErr.GuildID, testApplication, guildID)
+	}
+
+	if !strings.Contains(handlerErr.Handler, "TestHandlerErrorProvenance") {
+		t.Errorf("error has handler %q, want the listener's func", handlerErr.Handler)
+	}
+
+	if !errors.Is(handlerErr, errTest) {
+		t.Errorf("error does not wrap the listener's error")
+	}
+}
+
+func TestHandlerErrorParserFailed(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	nextHandlerError := receiveHandlerError(t, bot)
+
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventMessageCreate, json.RawMessage(`[]`)))
+
+	if handlerErr := nextHandlerError(); handlerErr.HandlerIndex != -1 || handlerErr.Handler != "" {
+		t.Errorf("parser error has handler %d (%s), want -1 ()", handlerErr.HandlerIndex, handlerErr.Handler)
+	}
+}
+
+func TestHandlerErrorString(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *sandwich.HandlerError
+		want string
+	}{
+		{
+			name: "parser",
+			err:  &sandwich.HandlerError{Event: "RESUMED", HandlerIndex: -1, Err: errTest},
+			want: "failed to parse RESUMED event: test error",
+		},
+		{
+			name: "listener",
+			err:  &sandwich.HandlerError{Event: "RESUMED", Handler: "main.onResumed", HandlerIndex: 0, Err: errTest},
+			want: "RESUMED handler 0 (main.onResumed) failed: test error",
+		},
+		{
+			name: "cog listener",
+			err:  &sandwich.HandlerError{Event: "RESUMED", Cog: "welcomer", Handler: "main.onResumed", HandlerIndex: 2, Err: errTest},
+			want: "RESUMED handler 2 (main.onResumed) of cog welcomer failed: test error",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.err.Error(); got != test.want {
+				t.Errorf("Error() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestListenerPanicRecovered(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      any
+		wantUnwrap error
+	}{
+		{name: "string", value: "boom", wantUnwrap: nil},
+		{name: "error", value: errTest, wantUnwrap: errTest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sandwichClient, bot := newTestBot()
+
+			nextHandlerError := receiveHandlerError(t, bot)
+			nextCalled := make(chan struct{})
+
+			bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+				panic(test.value)
+			})
+
+			bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+				close(nextCalled)
+
+				return nil
+			})
+
+			dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+			handlerErr := nextHandlerError()
+
+			select {
+			case <-nextCalled:
+			case <-time.After(time.Second * 5):
+				t.Error("listener after the panicking listener was not called")
+			}
+
+			var panicErr *sandwich.PanicError
+			if !errors.As(handlerErr, &panicErr) {
+				t.Fatalf("ERROR handler received %v, want a *sandwich.PanicError", handlerErr)
+			}
+
+			if panicErr.Value != test.value || len(panicErr.Stack) == 0 || !strings.Contains(panicErr.Handler, "TestListenerPanicRecovered") {
+				t.Errorf("panic error has value %v from %q with a %d byte stack", panicErr.Value, panicErr.Handler, len(panicErr.Stack))
+			}
+
+			if unwrapped := panicErr.Unwrap(); unwrapped != test.wantUnwrap {
+				t.Errorf("panic error unwraps to %v, want %v", unwrapped, test.wantUnwrap)
+			}
+		})
+	}
+}
+
+func TestParserPanicRecovered(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	nextHandlerError := receiveHandlerError(t, bot)
+
+	bot.Handlers.RegisterEventHandler("TEST_PANIC", func(*sandwich.EventContext, sandwich_daemon.ProducedPayload) error {
+		panic("boom")
+	})
+
+	dispatch(t, sandwichClient, newPayload("TEST_PANIC", json.RawMessage(`{}`)))
+
+	handlerErr := nextHandlerError()
+
+	var panicErr *sandwich.PanicError
+	if !errors.As(handlerErr, &panicErr) || handlerErr.HandlerIndex != -1 {
+		t.Errorf("ERROR handler received %v, want a parser *sandwich.PanicError", handlerErr)
+	}
+}
@@ -2,4 +2,8 @@ package internal
 
 const (
 	DiscordEventError = "ERROR"
+
+	SandwichEventGRPCConnected    = "SW_GRPC_CONNECTED"
+	SandwichEventGRPCDisconnected = "SW_GRPC_DISCONNECTED"
+	SandwichEventGRPCReconnecting = "SW_GRPC_RECONNECTING"
 )
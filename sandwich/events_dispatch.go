@@ -6,7 +6,10 @@ import (
 	"log/slog"
 	"maps"
 	"os"
+	"slices"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/WelcomerTeam/Discord/discord"
@@ -14,8 +17,41 @@ import (
 	sandwich_protobuf "github.com/WelcomerTeam/Sandwich-Daemon/proto"
 )
 
+// DropFullChannelEvents changes the default overflow policy of worker queues
+// from blocking the listener to dropping the newest event.
 var DropFullChannelEvents = os.Getenv("SANDWICH_DROP_FULL_CHANNEL_EVENTS") == "true"
 
+// DefaultQueueCapacity is the default number of events each worker queue can buffer.
+// A value of 0 leaves worker queues unbounded.
+var DefaultQueueCapacity = queueCapacityFromEnv(os.Getenv("SANDWICH_QUEUE_CAPACITY"))
+
+// queueCapacityFromEnv parses the SANDWICH_QUEUE_CAPACITY environment variable. Values that
+// are not a positive number leave worker queues unbounded.
+func queueCapacityFromEnv(value string) int {
+	if value == "" {
+		return 0
+	}
+
+	capacity, err := strconv.Atoi(value)
+	if err != nil || capacity < 0 {
+		slog.Warn("Ignoring invalid SANDWICH_QUEUE_CAPACITY, worker queues are unbounded", "value", value, "error", err)
+
+		return 0
+	}
+
+	return capacity
+}
+
+// EventPriority is used to decide which events are dropped first when a worker queue
+// using OverflowPolicyDropPriority is full. Events with a lower priority are dropped first.
+type EventPriority int8
+
+const (
+	EventPriorityLow    EventPriority = -1
+	EventPriorityNormal EventPriority = 0
+	EventPriorityHigh   EventPriority = 1
+)
+
 type WorkerMessage struct {
 	eventCtx *EventContext
 	payload  sandwich_daemon.ProducedPayload
@@ -26,17 +62,92 @@ type Handlers struct {
 	EventHandlers   map[string]*EventHandler
 
 	WorkerPoolMu sync.RWMutex
-	WorkerPool   map[int32]*ChannelBuffer[WorkerMessage]
+	WorkerPool   map[WorkerKey]*ChannelBuffer[WorkerMessage]
+
+	// PartitionFunc decides which worker queue a payload is dispatched to.
+	PartitionFunc PartitionFunc
+
+	dispatchLimiter atomic.Pointer[fairLimiter]
+
+	// QueueCapacity is the number of events each worker queue can buffer.
+	// A value of 0 leaves worker queues unbounded. Changes only apply to new queues.
+	QueueCapacity int
+
+	// QueueOverflowPolicy decides what happens when an event is dispatched to a full queue.
+	QueueOverflowPolicy OverflowPolicy
+
+	eventPrioritiesMu sync.RWMutex
+	EventPriorities   map[string]EventPriority
+
+	// PriorityLane dispatches events with EventPriorityHigh or higher to a separate queue
+	// for each partition, so they are not delayed by the backlog of other events.
+	// Events in the priority lane are also not held back by SetMaxConcurrentDispatches.
+	PriorityLane bool
+
+	droppedEventsMu sync.Mutex
+	droppedEvents   map[string]uint64
+
+	closed    atomic.Bool
+	workersWg sync.WaitGroup
+
+	// WorkerIdleTimeout is how long a worker can go without receiving an event before it
+	// is stopped and its queue removed. A value of 0 keeps workers running forever.
+	// Changes only apply to new workers.
+	WorkerIdleTimeout time.Duration
+
+	// HandlerConcurrency is the number of listeners of an event that can run at the same time.
+	// A value of 1 or less runs listeners one after another in the order they were registered.
+	HandlerConcurrency int
+
+	// HandlerTimeout is the deadline given to each listener of an event. Listeners should
+	// use the EventContext they receive as the context of any requests they make.
+	// A value of 0 removes the deadline.
+	HandlerTimeout time.Duration
+
+	eventTimeoutsMu sync.RWMutex
+	EventTimeouts   map[string]time.Duration
+
+	// SlowHandlerThreshold is how long a listener can run before it is reported as slow.
+	// A value of 0 disables reporting slow listeners.
+	SlowHandlerThreshold time.Duration
+
+	middlewaresMu sync.RWMutex
+	Middlewares   []Middleware
+
+	retryPoliciesMu sync.RWMutex
+	RetryPolicies   map[string]*RetryPolicy
+
+	retriesMu        sync.Mutex
+	retriesStopped   bool
+	retryStop        chan struct{}
+	retriesWg        sync.WaitGroup
+	abandonedRetries atomic.Int64
 }
 
 // SetupHandler ensures all nullable variables are properly constructed.
 func SetupHandler(handler *Handlers) *Handlers {
 	if handler == nil {
 		handler = &Handlers{
-			eventHandlersMu: sync.RWMutex{},
-			EventHandlers:   make(map[string]*EventHandler),
-			WorkerPoolMu:    sync.RWMutex{},
-			WorkerPool:      make(map[int32]*ChannelBuffer[WorkerMessage]),
+			eventHandlersMu:     sync.RWMutex{},
+			EventHandlers:       make(map[string]*EventHandler),
+			WorkerPoolMu:        sync.RWMutex{},
+			WorkerPool:          make(map[WorkerKey]*ChannelBuffer[WorkerMessage]),
+			PartitionFunc:       PartitionByShard,
+			QueueCapacity:       DefaultQueueCapacity,
+			QueueOverflowPolicy: OverflowPolicyBlock,
+			eventPrioritiesMu:   sync.RWMutex{},
+			EventPriorities:     make(map[string]EventPriority),
+			PriorityLane:        true,
+			droppedEventsMu:     sync.Mutex{},
+			droppedEvents:       make(map[string]uint64),
+			eventTimeoutsMu:     sync.RWMutex{},
+			EventTimeouts:       make(map[string]time.Duration),
+			retryPoliciesMu:     sync.RWMutex{},
+			RetryPolicies:       make(map[string]*RetryPolicy),
+		}
+
+		if DropFullChannelEvents {
+			handler.QueueOverflowPolicy = OverflowPolicyDropNewest
 		}
 	}
 
@@ -44,6 +155,30 @@ func SetupHandler(handler *Handlers) *Handlers {
 		handler.EventHandlers = make(map[string]*EventHandler)
 	}
 
+	if handler.WorkerPool == nil {
+		handler.WorkerPool = make(map[WorkerKey]*ChannelBuffer[WorkerMessage])
+	}
+
+	if handler.PartitionFunc == nil {
+		handler.PartitionFunc = PartitionByShard
+	}
+
+	if handler.EventPriorities == nil {
+		handler.EventPriorities = make(map[string]EventPriority)
+	}
+
+	if handler.RetryPolicies == nil {
+		handler.RetryPolicies = make(map[string]*RetryPolicy)
+	}
+
+	if handler.droppedEvents == nil {
+		handler.droppedEvents = make(map[string]uint64)
+	}
+
+	if handler.EventTimeouts == nil {
+		handler.EventTimeouts = make(map[string]time.Duration)
+	}
+
 	return handler
 }
 
@@ -115,6 +250,13 @@ func newDiscordHandlers() *Handlers {
 
 	handler.RegisterEventHandler(DiscordEventError, nil)
 
+	// Events that are safe to lose first when queues overflow.
+	handler.SetEventPriority(discord.DiscordEventPresenceUpdate, EventPriorityLow)
+	handler.SetEventPriority(discord.DiscordEventTypingStart, EventPriorityLow)
+
+	// Interactions must be acknowledged within three seconds, so are sent to the priority lane.
+	handler.SetEventPriority(discord.DiscordEventInteractionCreate, EventPriorityHigh)
+
 	return handler
 }
 
@@ -125,6 +267,10 @@ func newSandwichHandlers() *Handlers {
 	handler.RegisterEventHandler(sandwich_daemon.SandwichShardStatusUpdate, OnSandwichShardStatusUpdate)
 	handler.RegisterEventHandler(sandwich_daemon.SandwichApplicationStatusUpdate, OnSandwichApplicationStatusUpdate)
 
+	handler.RegisterEventHandler(SandwichEventGRPCConnected, OnSandwichGRPCConnected)
+	handler.RegisterEventHandler(SandwichEventGRPCDisconnected, OnSandwichGRPCDisconnected)
+	handler.RegisterEventHandler(SandwichEventGRPCReconnecting, OnSandwichGRPCReconnecting)
+
 	// Register events that are handled by default.
 	handler.RegisterOnSandwichConfigurationReload(func(eventCtx *EventContext) error {
 		identifiers, err := eventCtx.Sandwich.SandwichClient.FetchApplication(eventCtx.ToGRPCContext(), &sandwich_protobuf.ApplicationIdentifier{})
@@ -179,58 +325,420 @@ func (h *Handlers) RegisterEvent(eventName string, parser EventParser, event any
 	eventHandler := h.EventHandlers[eventName]
 
 	if event != nil {
+		listener := asEventListener(event)
+
+		// Funcs that no parser can call would otherwise be silently ignored.
+		if err := validateEventListener(eventName, listener); err != nil {
+			panic(fmt.Sprintf(`sandwich: RegisterEvent(%s): %v`, eventName, err.Error()))
+		}
+
 		eventHandler.EventsMu.Lock()
-		eventHandler.Events = append(eventHandler.Events, event)
+		eventHandler.Events = append(eventHandler.Events, listener)
 		eventHandler.EventsMu.Unlock()
 	}
 
 	return eventHandler
 }
 
+// RegisterEventListener adds a new func to an event and returns its listener,
+// which can be used to change how the func is dispatched.
+func (h *Handlers) RegisterEventListener(eventName string, event any) *EventListener {
+	listener := asEventListener(event)
+
+	h.RegisterEvent(eventName, nil, listener)
+
+	return listener
+}
+
 // RegisterEventHandler adds a new event handler. If there is already
 // an event registered with the name, it is overridden.
 func (h *Handlers) RegisterEventHandler(eventName string, parser EventParser) *EventHandler {
 	return h.RegisterEvent(eventName, parser, nil)
 }
 
-func (h *Handlers) getWorkerPool(eventCtx *EventContext, shardID int32) *ChannelBuffer[WorkerMessage] {
+// hasEventHandler returns true if an event has a parser registered.
+func (h *Handlers) hasEventHandler(eventName string) bool {
+	h.eventHandlersMu.RLock()
+	eventHandler, ok := h.EventHandlers[eventName]
+	h.eventHandlersMu.RUnlock()
+
+	return ok && eventHandler.Parser != nil
+}
+
+// SetQueueCapacity sets the number of events each worker queue can buffer.
+// A value of 0 leaves worker queues unbounded. Changes only apply to new queues.
+func (h *Handlers) SetQueueCapacity(capacity int) {
+	h.QueueCapacity = capacity
+}
+
+// SetQueueOverflowPolicy sets what happens when an event is dispatched to a full queue.
+// Changes only apply to new queues.
+func (h *Handlers) SetQueueOverflowPolicy(policy OverflowPolicy) {
+	h.QueueOverflowPolicy = policy
+}
+
+// SetPartitionFunc sets the function used to pick the worker queue of a payload,
+// such as PartitionByShard or PartitionByGuild. This should be set before any payloads are dispatched.
+func (h *Handlers) SetPartitionFunc(partitionFunc PartitionFunc) {
+	h.PartitionFunc = partitionFunc
+}
+
+// SetMaxConcurrentDispatches limits how many workers can dispatch events at the same time.
+// When workers are waiting for a slot, it is given to the application with the fewest
+// events currently being dispatched. A limit of 0 or less removes the limit.
+func (h *Handlers) SetMaxConcurrentDispatches(limit int) {
+	if limit <= 0 {
+		h.dispatchLimiter.Store(nil)
+	} else {
+		h.dispatchLimiter.Store(newFairLimiter(limit))
+	}
+}
+
+// SetHandlerConcurrency sets the number of listeners of an event that can run at the same time.
+// A value of 1 or less runs listeners one after another in the order they were registered.
+func (h *Handlers) SetHandlerConcurrency(concurrency int) {
+	h.HandlerConcurrency = concurrency
+}
+
+// SetWorkerIdleTimeout sets how long a worker can go without receiving an event before it is stopped.
+// A value of 0 keeps workers running forever. Changes only apply to new workers.
+func (h *Handlers) SetWorkerIdleTimeout(timeout time.Duration) {
+	h.WorkerIdleTimeout = timeout
+}
+
+// SetHandlerTimeout sets the deadline given to each listener of an event, applied to the
+// context of the EventContext the listener receives. A value of 0 removes the deadline.
+func (h *Handlers) SetHandlerTimeout(timeout time.Duration) {
+	h.HandlerTimeout = timeout
+}
+
+// SetEventTimeout overrides the handler timeout for listeners of a specific event type.
+// A value of 0 removes the deadline for the event type.
+func (h *Handlers) SetEventTimeout(eventName string, timeout time.Duration) {
+	h.eventTimeoutsMu.Lock()
+	h.EventTimeouts[eventName] = timeout
+	h.eventTimeoutsMu.Unlock()
+}
+
+// GetEventTimeout returns the deadline given to each listener of an event type.
+func (h *Handlers) GetEventTimeout(eventName string) time.Duration {
+	h.eventTimeoutsMu.RLock()
+	timeout, ok := h.EventTimeouts[eventName]
+	h.eventTimeoutsMu.RUnlock()
+
+	if !ok {
+		return h.HandlerTimeout
+	}
+
+	return timeout
+}
+
+// SetSlowHandlerThreshold sets how long a listener can run before it is reported as slow.
+// A value of 0 disables reporting slow listeners.
+func (h *Handlers) SetSlowHandlerThreshold(threshold time.Duration) {
+	h.SlowHandlerThreshold = threshold
+}
+
+// SetEventPriority sets the priority of an event type.
+// Events without a priority are treated as EventPriorityNormal.
+func (h *Handlers) SetEventPriority(eventName string, priority EventPriority) {
+	h.eventPrioritiesMu.Lock()
+	h.EventPriorities[eventName] = priority
+	h.eventPrioritiesMu.Unlock()
+}
+
+// SetPriorityLane sets if events with EventPriorityHigh or higher are dispatched to a
+// separate queue, skipping the backlog of other events in their partition.
+func (h *Handlers) SetPriorityLane(enabled bool) {
+	h.PriorityLane = enabled
+}
+
+// GetEventPriority returns the priority of an event type.
+func (h *Handlers) GetEventPriority(eventName string) EventPriority {
+	h.eventPrioritiesMu.RLock()
+	priority := h.EventPriorities[eventName]
+	h.eventPrioritiesMu.RUnlock()
+
+	return priority
+}
+
+// DroppedEvents returns the number of events dropped by full worker queues, by event type.
+func (h *Handlers) DroppedEvents() map[string]uint64 {
+	h.droppedEventsMu.Lock()
+	droppedEvents := maps.Clone(h.droppedEvents)
+	h.droppedEventsMu.Unlock()
+
+	return droppedEvents
+}
+
+func (h *Handlers) onDroppedEvent(channelBuffer *ChannelBuffer[WorkerMessage], msg WorkerMessage) {
+	h.droppedEventsMu.Lock()
+	h.droppedEvents[msg.payload.Type]++
+	h.droppedEventsMu.Unlock()
+
+	msg.eventCtx.Logger.Debug("Dropped event from full queue",
+		"type", msg.payload.Type,
+		"application", msg.payload.Metadata.Application,
+		"shard", msg.payload.Metadata.Shard[1],
+		"policy", channelBuffer.Policy().String())
+
+	msg.eventCtx.Sandwich.deadLetter(msg.eventCtx.Context, newDeadLetter(msg.eventCtx, DeadLetterQueueOverflow, nil))
+
+	msg.eventCtx.dispatched(&DispatchResult{Event: msg.payload.Type, Dropped: true})
+}
+
+func (h *Handlers) workerMessagePriority(msg WorkerMessage) int {
+	return int(h.GetEventPriority(msg.payload.Type))
+}
+
+func (h *Handlers) getWorkerPool(eventCtx *EventContext, key WorkerKey) *ChannelBuffer[WorkerMessage] {
 	h.WorkerPoolMu.Lock()
 	defer h.WorkerPoolMu.Unlock()
 
-	channelBuffer, ok := h.WorkerPool[shardID]
+	// The queues of closed handlers are kept until their workers finish but no longer accept events.
+	if h.closed.Load() {
+		return nil
+	}
+
+	channelBuffer, ok := h.WorkerPool[key]
 	if ok {
 		return channelBuffer
 	}
 
-	channelBuffer = NewChannelBuffer[WorkerMessage]()
+	// The policy of the queue is logged, as the policy of the handlers may have changed since it was created.
+	channelBuffer = NewBoundedChannelBuffer(h.QueueCapacity, h.QueueOverflowPolicy, h.workerMessagePriority, func(msg WorkerMessage) {
+		h.onDroppedEvent(channelBuffer, msg)
+	})
+
+	h.WorkerPool[key] = channelBuffer
 
-	h.WorkerPool[shardID] = channelBuffer
-	go h.worker(eventCtx.Logger, shardID, channelBuffer)
+	h.workersWg.Add(1)
+	go h.worker(eventCtx.Logger, key, channelBuffer)
 
 	return channelBuffer
 }
 
-func (h *Handlers) worker(l *slog.Logger, shardID int32, channelBuffer *ChannelBuffer[WorkerMessage]) {
-	for msg := range channelBuffer.Out {
-		h.DispatchType(msg.eventCtx, msg.payload.Type, msg.payload)
+// WorkerPoolInfo describes a live worker and its queue.
+type WorkerPoolInfo struct {
+	Key WorkerKey
+
+	ChannelBufferStats
+}
+
+// WorkerPools returns information about every live worker.
+func (h *Handlers) WorkerPools() []WorkerPoolInfo {
+	h.WorkerPoolMu.RLock()
+	defer h.WorkerPoolMu.RUnlock()
+
+	workerPools := make([]WorkerPoolInfo, 0, len(h.WorkerPool))
+
+	for key, channelBuffer := range h.WorkerPool {
+		workerPools = append(workerPools, WorkerPoolInfo{
+			Key:                key,
+			ChannelBufferStats: channelBuffer.Stats(),
+		})
+	}
+
+	return workerPools
+}
+
+// CloseWorkerPool removes the queue of a worker. The worker stops once it has handled the
+// events already queued. Events dispatched to the key afterwards start a new worker.
+// Returns false if there is no worker with the key.
+func (h *Handlers) CloseWorkerPool(key WorkerKey) bool {
+	h.WorkerPoolMu.Lock()
+	channelBuffer, ok := h.WorkerPool[key]
+	delete(h.WorkerPool, key)
+	h.WorkerPoolMu.Unlock()
+
+	if ok {
+		channelBuffer.Close()
+	}
+
+	return ok
+}
+
+// CloseApplicationWorkerPools removes the queues of every worker of an application,
+// such as when it has been removed or resharded. Returns the number of workers closed.
+func (h *Handlers) CloseApplicationWorkerPools(application string) int {
+	h.WorkerPoolMu.Lock()
+
+	channelBuffers := make([]*ChannelBuffer[WorkerMessage], 0)
+
+	for key, channelBuffer := range h.WorkerPool {
+		if key.Application == application {
+			channelBuffers = append(channelBuffers, channelBuffer)
+			delete(h.WorkerPool, key)
+		}
+	}
+
+	h.WorkerPoolMu.Unlock()
+
+	for _, channelBuffer := range channelBuffers {
+		channelBuffer.Close()
+	}
+
+	return len(channelBuffers)
+}
+
+// retireWorkerPool removes the queue of an idle worker, if it is still empty and has not
+// been replaced. The queue is only closed if nothing is waiting to be handled by the worker,
+// so a worker started for events pushed afterwards cannot run ahead of it.
+func (h *Handlers) retireWorkerPool(key WorkerKey, channelBuffer *ChannelBuffer[WorkerMessage]) bool {
+	h.WorkerPoolMu.Lock()
+	defer h.WorkerPoolMu.Unlock()
+
+	if h.WorkerPool[key] != channelBuffer || !channelBuffer.CloseIfEmpty() {
+		return false
+	}
+
+	delete(h.WorkerPool, key)
+
+	return true
+}
+
+func (h *Handlers) worker(l *slog.Logger, key WorkerKey, channelBuffer *ChannelBuffer[WorkerMessage]) {
+	defer h.workersWg.Done()
+
+	var (
+		idle        <-chan time.Time
+		idleTimer   *time.Timer
+		idleTimeout = h.WorkerIdleTimeout
+	)
+
+	if idleTimeout > 0 {
+		idleTimer = time.NewTimer(idleTimeout)
+		defer idleTimer.Stop()
+
+		idle = idleTimer.C
+	}
+
+	for {
+		select {
+		case msg, ok := <-channelBuffer.Out:
+			if !ok {
+				l.Debug("Stopped worker",
+					"application", key.Application,
+					"partition", key.Partition,
+					"priorityLane", key.PriorityLane)
+
+				return
+			}
+
+			h.handleWorkerMessage(key, msg)
+
+			if idleTimer != nil {
+				idleTimer.Reset(idleTimeout)
+			}
+		case <-idle:
+			// Once retired, the worker keeps receiving until the queue is closed in
+			// case an event was pushed whilst it was being retired.
+			if !h.retireWorkerPool(key, channelBuffer) {
+				idleTimer.Reset(idleTimeout)
+			}
+		}
+	}
+}
+
+func (h *Handlers) handleWorkerMessage(key WorkerKey, msg WorkerMessage) {
+	limiter := h.dispatchLimiter.Load()
+	if limiter != nil && !key.PriorityLane {
+		limiter.Acquire(key.Application)
+		defer limiter.Release(key.Application)
+	}
+
+	h.dispatchEvent(msg.eventCtx, msg.payload)
+
+	msg.eventCtx.settleDispatch()
+}
+
+// dispatchEvent dispatches a payload on the current goroutine. Errors returned by the
+// parser are passed to the ERROR handlers and the dead-letter sink.
+func (h *Handlers) dispatchEvent(eventCtx *EventContext, payload sandwich_daemon.ProducedPayload) {
+	err := h.DispatchType(eventCtx, payload.Type, payload)
+	if err != nil {
+		handlerErr := newHandlerError(eventCtx, nil, -1, err)
+		eventCtx.collectError(handlerErr)
+
+		h.WrapFuncType(eventCtx, handlerErr)
+
+		eventCtx.Sandwich.deadLetter(eventCtx.Context, newDeadLetter(eventCtx, DeadLetterParserFailed, err))
 	}
 }
 
 // Dispatch dispatches a payload. All dispatched events will be sent through a goroutine, so
-// no errors are returned.
+// no errors are returned. If the worker queue is full, the queue's overflow policy is applied
+// and the event may be dropped or this may block until there is space.
+// High priority events, such as INTERACTION_CREATE, are sent to the priority lane of their
+// partition unless the priority lane is disabled.
 func (h *Handlers) Dispatch(eventCtx *EventContext, payload sandwich_daemon.ProducedPayload) {
-	shardID := payload.Metadata.Shard[1]
+	key := h.PartitionFunc(&payload)
+	key.PriorityLane = h.PriorityLane && h.GetEventPriority(payload.Type) >= EventPriorityHigh
 
-	channelBuffer := h.getWorkerPool(eventCtx, shardID)
-	channelBuffer.Push(WorkerMessage{
-		eventCtx: eventCtx,
-		payload:  payload,
-	})
+	for {
+		channelBuffer := h.getWorkerPool(eventCtx, key)
+		if channelBuffer == nil {
+			eventCtx.Logger.Debug("Ignored event dispatched after handlers closed", "type", payload.Type)
+
+			eventCtx.dispatched(&DispatchResult{Event: payload.Type, Dropped: true})
+
+			return
+		}
+
+		// If the queue was retired after it was fetched, fetch a new one.
+		if channelBuffer.Push(WorkerMessage{
+			eventCtx: eventCtx,
+			payload:  payload,
+		}) || !channelBuffer.Closed() {
+			return
+		}
+	}
+}
+
+// Close stops the handlers from accepting new events and waits for every worker to finish
+// the events already queued. If the context is done before then, any events still queued
+// are discarded and the number of discarded events is returned along with the context error.
+// Listener retries that are still waiting are cancelled and counted as abandoned.
+func (h *Handlers) Close(ctx context.Context) (abandoned int, err error) {
+	h.WorkerPoolMu.Lock()
+	h.closed.Store(true)
+	channelBuffers := slices.Collect(maps.Values(h.WorkerPool))
+	h.WorkerPoolMu.Unlock()
+
+	for _, channelBuffer := range channelBuffers {
+		channelBuffer.Close()
+	}
+
+	retriesStopped := h.stopRetries()
+
+	drained := make(chan struct{})
+
+	go func() {
+		h.workersWg.Wait()
+		<-retriesStopped
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return int(h.abandonedRetries.Load()), nil
+	case <-ctx.Done():
+		for _, channelBuffer := range channelBuffers {
+			for _, msg := range channelBuffer.Drain() {
+				msg.eventCtx.dispatched(&DispatchResult{Event: msg.payload.Type, Dropped: true})
+
+				abandoned++
+			}
+		}
+
+		return abandoned + int(h.abandonedRetries.Load()), ctx.Err()
+	}
 }
 
 // DispatchType is similar to Dispatch however a custom event name
-// can. be passed, preserving the original payload.
-func (h *Handlers) DispatchType(eventCtx *EventContext, eventName string, payload sandwich_daemon.ProducedPayload) error {
+// can. be passed, preserving the original payload. Middleware added with Use
+// runs around the parser of the event.
+func (h *Handlers) DispatchType(eventCtx *EventContext, eventName string, payload sandwich_daemon.ProducedPayload) (err error) {
 	if payload.Metadata.Application != "" {
 		identifier, ok, err := eventCtx.Sandwich.FetchIdentifier(context.TODO(), payload.Metadata.Application)
 		if !ok || err != nil {
@@ -257,16 +765,24 @@ func (h *Handlers) DispatchType(eventCtx *EventContext, eventName string, payloa
 	defer func() {
 		errorValue := recover()
 		if errorValue != nil {
-			eventCtx.Sandwich.RecoverEventPanic(errorValue, eventCtx, &payload)
+			panicErr := newPanicError(errorValue, "")
+			eventCtx.Sandwich.handleEventPanic(eventCtx, &payload, panicErr)
+
+			err = panicErr
 		}
 	}()
 
-	return eventHandler.Parser(eventCtx, payload)
+	dispatch := h.withMiddleware(func(eventCtx *EventContext, _ string, payload sandwich_daemon.ProducedPayload) error {
+		return eventHandler.Parser(eventCtx, payload)
+	})
+
+	return dispatch(eventCtx, eventName, payload)
 }
 
 // WrapFuncType handles the error of a FuncType if it returns an error.
 // It will call any ERROR handlers. Errors that occur in the ERROR handler
-// will not trigger the ERROR handler.
+// will not trigger the ERROR handler. Errors from dispatched events are
+// passed to the ERROR handlers as a *HandlerError.
 func (h *Handlers) WrapFuncType(eventCtx *EventContext, funcTypeErr error) error {
 	if funcTypeErr != nil {
 		if ev, ok := h.EventHandlers["ERROR"]; ok {
@@ -274,7 +790,7 @@ func (h *Handlers) WrapFuncType(eventCtx *EventContext, funcTypeErr error) error
 			defer ev.EventsMu.RUnlock()
 
 			for _, event := range ev.Events {
-				if f, ok := event.(OnErrorFuncType); ok {
+				if f, ok := asEventListener(event).Func.(OnErrorFuncType); ok {
 					_ = f(eventCtx, funcTypeErr)
 				}
 			}
@@ -291,14 +807,9 @@ func OnReady(eventCtx *EventContext, payload sandwich_daemon.ProducedPayload) er
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnReadyFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnReadyFuncType) error {
+		return f(eventCtx)
+	})
 
 	return nil
 }
@@ -312,14 +823,9 @@ func OnResumed(eventCtx *EventContext, payload sandwich_daemon.ProducedPayload)
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnResumedFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnResumedFuncType) error {
+		return f(eventCtx)
+	})
 
 	return nil
 }
@@ -337,14 +843,9 @@ func OnApplicationCommandCreate(eventCtx *EventContext, payload sandwich_daemon.
 		eventCtx.Guild = NewGuild(*applicationCommandCreatePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnApplicationCommandCreateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.ApplicationCommand(applicationCommandCreatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnApplicationCommandCreateFuncType) error {
+		return f(eventCtx, discord.ApplicationCommand(applicationCommandCreatePayload))
+	})
 
 	return nil
 }
@@ -362,14 +863,9 @@ func OnApplicationCommandUpdate(eventCtx *EventContext, payload sandwich_daemon.
 		eventCtx.Guild = NewGuild(*applicationCommandUpdatePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnApplicationCommandUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.ApplicationCommand(applicationCommandUpdatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnApplicationCommandUpdateFuncType) error {
+		return f(eventCtx, discord.ApplicationCommand(applicationCommandUpdatePayload))
+	})
 
 	return nil
 }
@@ -387,14 +883,9 @@ func OnApplicationCommandDelete(eventCtx *EventContext, payload sandwich_daemon.
 		eventCtx.Guild = NewGuild(*applicationCommandDeletePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnApplicationCommandDeleteFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.ApplicationCommand(applicationCommandDeletePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnApplicationCommandDeleteFuncType) error {
+		return f(eventCtx, discord.ApplicationCommand(applicationCommandDeletePayload))
+	})
 
 	return nil
 }
@@ -412,14 +903,9 @@ func OnChannelCreate(eventCtx *EventContext, payload sandwich_daemon.ProducedPay
 		eventCtx.Guild = NewGuild(*channelCreatePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnChannelCreateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.Channel(channelCreatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnChannelCreateFuncType) error {
+		return f(eventCtx, discord.Channel(channelCreatePayload))
+	})
 
 	return nil
 }
@@ -442,14 +928,9 @@ func OnChannelUpdate(eventCtx *EventContext, payload sandwich_daemon.ProducedPay
 		return fmt.Errorf("failed to unmarshal extra: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnChannelUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, beforeChannel, discord.Channel(channelUpdatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnChannelUpdateFuncType) error {
+		return f(eventCtx, beforeChannel, discord.Channel(channelUpdatePayload))
+	})
 
 	return nil
 }
@@ -467,14 +948,9 @@ func OnChannelDelete(eventCtx *EventContext, payload sandwich_daemon.ProducedPay
 		eventCtx.Guild = NewGuild(*channelDeletePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnChannelDeleteFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.Channel(channelDeletePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnChannelDeleteFuncType) error {
+		return f(eventCtx, discord.Channel(channelDeletePayload))
+	})
 
 	return nil
 }
@@ -492,14 +968,9 @@ func OnChannelPinsUpdate(eventCtx *EventContext, payload sandwich_daemon.Produce
 
 	channel := NewChannel(&channelPinsUpdatePayload.GuildID, channelPinsUpdatePayload.ChannelID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnChannelPinsUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, channel, channelPinsUpdatePayload.LastPinTimestamp))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnChannelPinsUpdateFuncType) error {
+		return f(eventCtx, channel, channelPinsUpdatePayload.LastPinTimestamp)
+	})
 
 	return nil
 }
@@ -513,14 +984,9 @@ func OnEntitlementCreate(eventCtx *EventContext, payload sandwich_daemon.Produce
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnEntitlementCreateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, entitlementPayload))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnEntitlementCreateFuncType) error {
+		return f(eventCtx, entitlementPayload)
+	})
 
 	return nil
 }
@@ -534,14 +1000,9 @@ func OnEntitlementUpdate(eventCtx *EventContext, payload sandwich_daemon.Produce
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnEntitlementUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, entitlementPayload))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnEntitlementUpdateFuncType) error {
+		return f(eventCtx, entitlementPayload)
+	})
 
 	return nil
 }
@@ -555,14 +1016,9 @@ func OnEntitlementDelete(eventCtx *EventContext, payload sandwich_daemon.Produce
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnEntitlementDeleteFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, entitlementPayload))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnEntitlementDeleteFuncType) error {
+		return f(eventCtx, entitlementPayload)
+	})
 
 	return nil
 }
@@ -580,14 +1036,9 @@ func OnThreadCreate(eventCtx *EventContext, payload sandwich_daemon.ProducedPayl
 		eventCtx.Guild = NewGuild(*threadCreatePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnThreadCreateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.Channel(threadCreatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnThreadCreateFuncType) error {
+		return f(eventCtx, discord.Channel(threadCreatePayload))
+	})
 
 	return nil
 }
@@ -610,14 +1061,9 @@ func OnThreadUpdate(eventCtx *EventContext, payload sandwich_daemon.ProducedPayl
 		return fmt.Errorf("failed to unmarshal extra: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnThreadUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, beforeChannel, discord.Channel(threadUpdatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnThreadUpdateFuncType) error {
+		return f(eventCtx, beforeChannel, discord.Channel(threadUpdatePayload))
+	})
 
 	return nil
 }
@@ -635,14 +1081,9 @@ func OnThreadDelete(eventCtx *EventContext, payload sandwich_daemon.ProducedPayl
 		eventCtx.Guild = NewGuild(*threadDeletePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnThreadDeleteFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.Channel(threadDeletePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnThreadDeleteFuncType) error {
+		return f(eventCtx, discord.Channel(threadDeletePayload))
+	})
 
 	return nil
 }
@@ -678,16 +1119,16 @@ func OnThreadMemberUpdate(eventCtx *EventContext, payload sandwich_daemon.Produc
 		eventCtx.Guild = NewGuild(*threadMemberUpdatePayload.GuildID)
 	}
 
-	channel := NewChannel(threadMemberUpdatePayload.GuildID, *threadMemberUpdatePayload.UserID)
+	var threadID discord.Snowflake
+	if threadMemberUpdatePayload.ID != nil {
+		threadID = *threadMemberUpdatePayload.ID
+	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
+	channel := NewChannel(threadMemberUpdatePayload.GuildID, threadID)
 
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnThreadMemberUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, channel, discord.ThreadMember(threadMemberUpdatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnThreadMemberUpdateFuncType) error {
+		return f(eventCtx, channel, discord.ThreadMember(threadMemberUpdatePayload))
+	})
 
 	return nil
 }
@@ -707,7 +1148,9 @@ func OnThreadMembersUpdate(eventCtx *EventContext, payload sandwich_daemon.Produ
 
 	addedUsers := make([]*discord.User, 0, len(threadMembersUpdatePayload.AddedMembers))
 	for _, addedMember := range threadMembersUpdatePayload.AddedMembers {
-		addedUsers = append(addedUsers, NewUser(*addedMember.UserID))
+		if addedMember.UserID != nil {
+			addedUsers = append(addedUsers, NewUser(*addedMember.UserID))
+		}
 	}
 
 	removedUsers := make([]*discord.User, 0, len(threadMembersUpdatePayload.RemovedMemberIDs))
@@ -715,14 +1158,9 @@ func OnThreadMembersUpdate(eventCtx *EventContext, payload sandwich_daemon.Produ
 		removedUsers = append(removedUsers, NewUser(removedUser))
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnThreadMembersUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, channel, addedUsers, removedUsers))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnThreadMembersUpdateFuncType) error {
+		return f(eventCtx, channel, addedUsers, removedUsers)
+	})
 
 	return nil
 }
@@ -776,14 +1214,9 @@ func OnGuildUpdate(eventCtx *EventContext, payload sandwich_daemon.ProducedPaylo
 		return fmt.Errorf("failed to unmarshal extra: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, beforeGuild, guild))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildUpdateFuncType) error {
+		return f(eventCtx, beforeGuild, guild)
+	})
 
 	return nil
 }
@@ -817,14 +1250,9 @@ func OnGuildAuditLogEntryCreate(eventCtx *EventContext, payload sandwich_daemon.
 
 	eventCtx.Guild = NewGuild(guildAuditLogEntryCreatePayload.GuildID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildAuditLogEntryCreateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, guildAuditLogEntryCreatePayload.GuildID, guildAuditLogEntryCreatePayload.AuditLogEntry))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildAuditLogEntryCreateFuncType) error {
+		return f(eventCtx, guildAuditLogEntryCreatePayload.GuildID, guildAuditLogEntryCreatePayload.AuditLogEntry)
+	})
 
 	return nil
 }
@@ -840,14 +1268,9 @@ func OnGuildBanAdd(eventCtx *EventContext, payload sandwich_daemon.ProducedPaylo
 		eventCtx.Guild = NewGuild(*guildBanAddPayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildBanAddFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, guildBanAddPayload.User))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildBanAddFuncType) error {
+		return f(eventCtx, guildBanAddPayload.User)
+	})
 
 	return nil
 }
@@ -865,14 +1288,9 @@ func OnGuildBanRemove(eventCtx *EventContext, payload sandwich_daemon.ProducedPa
 		eventCtx.Guild = NewGuild(*guildBanRemovePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildBanRemoveFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, guildBanRemovePayload.User))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildBanRemoveFuncType) error {
+		return f(eventCtx, guildBanRemovePayload.User)
+	})
 
 	return nil
 }
@@ -896,14 +1314,9 @@ func OnGuildEmojisUpdate(eventCtx *EventContext, payload sandwich_daemon.Produce
 	after := make([]discord.Emoji, 0, len(guildEmojisUpdatePayload.Emojis))
 	after = append(after, guildEmojisUpdatePayload.Emojis...)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildEmojisUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, before, after))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildEmojisUpdateFuncType) error {
+		return f(eventCtx, before, after)
+	})
 
 	return nil
 }
@@ -927,14 +1340,9 @@ func OnGuildStickersUpdate(eventCtx *EventContext, payload sandwich_daemon.Produ
 	after := make([]discord.Sticker, 0, len(guildStickersUpdatePayload.Stickers))
 	after = append(after, guildStickersUpdatePayload.Stickers...)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildStickersUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, before, after))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildStickersUpdateFuncType) error {
+		return f(eventCtx, before, after)
+	})
 
 	return nil
 }
@@ -950,14 +1358,9 @@ func OnGuildIntegrationsUpdate(eventCtx *EventContext, payload sandwich_daemon.P
 
 	eventCtx.Guild = NewGuild(guildIntegrationsUpdatePayload.GuildID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildIntegrationsUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildIntegrationsUpdateFuncType) error {
+		return f(eventCtx)
+	})
 
 	return nil
 }
@@ -971,17 +1374,14 @@ func OnGuildMemberAdd(eventCtx *EventContext, payload sandwich_daemon.ProducedPa
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	eventCtx.Guild = NewGuild(*guildMemberAddPayload.GuildID)
-
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildMemberAddFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.GuildMember(guildMemberAddPayload)))
-		}
+	if guildMemberAddPayload.GuildID != nil {
+		eventCtx.Guild = NewGuild(*guildMemberAddPayload.GuildID)
 	}
 
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildMemberAddFuncType) error {
+		return f(eventCtx, discord.GuildMember(guildMemberAddPayload))
+	})
+
 	return nil
 }
 
@@ -996,14 +1396,9 @@ func OnGuildMemberRemove(eventCtx *EventContext, payload sandwich_daemon.Produce
 
 	eventCtx.Guild = NewGuild(guildMemberRemovePayload.GuildID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildMemberRemoveFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, guildMemberRemovePayload.User))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildMemberRemoveFuncType) error {
+		return f(eventCtx, guildMemberRemovePayload.User)
+	})
 
 	return nil
 }
@@ -1017,21 +1412,18 @@ func OnGuildMemberUpdate(eventCtx *EventContext, payload sandwich_daemon.Produce
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	eventCtx.Guild = NewGuild(*guildMemberUpdatePayload.GuildID)
+	if guildMemberUpdatePayload.GuildID != nil {
+		eventCtx.Guild = NewGuild(*guildMemberUpdatePayload.GuildID)
+	}
 
 	var beforeGuildMember discord.GuildMember
 	if _, err := eventCtx.DecodeExtra(payload, "before", &beforeGuildMember); err != nil {
 		return fmt.Errorf("failed to unmarshal extra: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildMemberUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, beforeGuildMember, discord.GuildMember(guildMemberUpdatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildMemberUpdateFuncType) error {
+		return f(eventCtx, beforeGuildMember, discord.GuildMember(guildMemberUpdatePayload))
+	})
 
 	return nil
 }
@@ -1049,14 +1441,9 @@ func OnGuildRoleCreate(eventCtx *EventContext, payload sandwich_daemon.ProducedP
 		eventCtx.Guild = NewGuild(guildRoleCreatePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildRoleCreateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.Role(guildRoleCreatePayload.Role)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildRoleCreateFuncType) error {
+		return f(eventCtx, discord.Role(guildRoleCreatePayload.Role))
+	})
 
 	return nil
 }
@@ -1079,14 +1466,9 @@ func OnGuildRoleUpdate(eventCtx *EventContext, payload sandwich_daemon.ProducedP
 		return fmt.Errorf("failed to unmarshal extra: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildRoleUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, beforeRole, guildRoleUpdatePayload.Role))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildRoleUpdateFuncType) error {
+		return f(eventCtx, beforeRole, guildRoleUpdatePayload.Role)
+	})
 
 	return nil
 }
@@ -1102,14 +1484,9 @@ func OnGuildRoleDelete(eventCtx *EventContext, payload sandwich_daemon.ProducedP
 
 	eventCtx.Guild = NewGuild(guildRoleDeletePayload.GuildID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildRoleDeleteFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, guildRoleDeletePayload.RoleID))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildRoleDeleteFuncType) error {
+		return f(eventCtx, guildRoleDeletePayload.RoleID)
+	})
 
 	return nil
 }
@@ -1127,14 +1504,9 @@ func OnIntegrationCreate(eventCtx *EventContext, payload sandwich_daemon.Produce
 		eventCtx.Guild = NewGuild(*integrationCreatePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnIntegrationCreateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.Integration(integrationCreatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnIntegrationCreateFuncType) error {
+		return f(eventCtx, discord.Integration(integrationCreatePayload))
+	})
 
 	return nil
 }
@@ -1157,14 +1529,9 @@ func OnIntegrationUpdate(eventCtx *EventContext, payload sandwich_daemon.Produce
 		return fmt.Errorf("failed to unmarshal extra: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnIntegrationUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, beforeIntegration, discord.Integration(integrationUpdatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnIntegrationUpdateFuncType) error {
+		return f(eventCtx, beforeIntegration, discord.Integration(integrationUpdatePayload))
+	})
 
 	return nil
 }
@@ -1185,14 +1552,9 @@ func OnIntegrationDelete(eventCtx *EventContext, payload sandwich_daemon.Produce
 		applicationID = integrationDeletePayload.ApplicationID
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnIntegrationDeleteFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, integrationDeletePayload.ID, applicationID))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnIntegrationDeleteFuncType) error {
+		return f(eventCtx, integrationDeletePayload.ID, applicationID)
+	})
 
 	return nil
 }
@@ -1210,14 +1572,9 @@ func OnInteractionCreate(eventCtx *EventContext, payload sandwich_daemon.Produce
 		eventCtx.Guild = NewGuild(*interactionCreatePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnInteractionCreateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.Interaction(interactionCreatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnInteractionCreateFuncType) error {
+		return f(eventCtx, discord.Interaction(interactionCreatePayload))
+	})
 
 	return nil
 }
@@ -1235,14 +1592,9 @@ func OnInviteCreate(eventCtx *EventContext, payload sandwich_daemon.ProducedPayl
 		eventCtx.Guild = NewGuild(*inviteCreatePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnInviteCreateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.Invite(inviteCreatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnInviteCreateFuncType) error {
+		return f(eventCtx, discord.Invite(inviteCreatePayload))
+	})
 
 	return nil
 }
@@ -1260,14 +1612,9 @@ func OnInviteDelete(eventCtx *EventContext, payload sandwich_daemon.ProducedPayl
 		eventCtx.Guild = NewGuild(*inviteDeletePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnInviteDeleteFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.Invite(inviteDeletePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnInviteDeleteFuncType) error {
+		return f(eventCtx, discord.Invite(inviteDeletePayload))
+	})
 
 	return nil
 }
@@ -1285,14 +1632,9 @@ func OnMessageCreate(eventCtx *EventContext, payload sandwich_daemon.ProducedPay
 		eventCtx.Guild = NewGuild(*messageCreatePayload.GuildID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnMessageCreateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.Message(messageCreatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnMessageCreateFuncType) error {
+		return f(eventCtx, discord.Message(messageCreatePayload))
+	})
 
 	return nil
 }
@@ -1315,14 +1657,9 @@ func OnMessageUpdate(eventCtx *EventContext, payload sandwich_daemon.ProducedPay
 		return fmt.Errorf("failed to unmarshal extra: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnMessageUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, beforeMessage, discord.Message(messageUpdatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnMessageUpdateFuncType) error {
+		return f(eventCtx, beforeMessage, discord.Message(messageUpdatePayload))
+	})
 
 	return nil
 }
@@ -1342,14 +1679,9 @@ func OnMessageDelete(eventCtx *EventContext, payload sandwich_daemon.ProducedPay
 
 	channel := NewChannel(messageDeletePayload.GuildID, messageDeletePayload.ChannelID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnMessageDeleteFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, channel, messageDeletePayload.ID))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnMessageDeleteFuncType) error {
+		return f(eventCtx, channel, messageDeletePayload.ID)
+	})
 
 	return nil
 }
@@ -1369,14 +1701,9 @@ func OnMessageDeleteBulk(eventCtx *EventContext, payload sandwich_daemon.Produce
 
 	channel := NewChannel(messageDeleteBulkPayload.GuildID, messageDeleteBulkPayload.ChannelID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnMessageDeleteBulkFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, channel, messageDeleteBulkPayload.IDs))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnMessageDeleteBulkFuncType) error {
+		return f(eventCtx, channel, messageDeleteBulkPayload.IDs)
+	})
 
 	return nil
 }
@@ -1399,14 +1726,9 @@ func OnMessageReactionAdd(eventCtx *EventContext, payload sandwich_daemon.Produc
 		guildMember = *messageReactionAddPayload.Member
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnMessageReactionAddFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, channel, messageReactionAddPayload.MessageID, messageReactionAddPayload.Emoji, guildMember))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnMessageReactionAddFuncType) error {
+		return f(eventCtx, channel, messageReactionAddPayload.MessageID, messageReactionAddPayload.Emoji, guildMember)
+	})
 
 	return nil
 }
@@ -1427,14 +1749,9 @@ func OnMessageReactionRemove(eventCtx *EventContext, payload sandwich_daemon.Pro
 	channel := NewChannel(messageReactionRemovePayload.GuildID, messageReactionRemovePayload.ChannelID)
 	user := NewUser(messageReactionRemovePayload.UserID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnMessageReactionRemoveFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, channel, messageReactionRemovePayload.MessageID, messageReactionRemovePayload.Emoji, user))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnMessageReactionRemoveFuncType) error {
+		return f(eventCtx, channel, messageReactionRemovePayload.MessageID, messageReactionRemovePayload.Emoji, user)
+	})
 
 	return nil
 }
@@ -1452,14 +1769,9 @@ func OnMessageReactionRemoveAll(eventCtx *EventContext, payload sandwich_daemon.
 
 	channel := NewChannel(&messageReactionRemoveAllPayload.GuildID, messageReactionRemoveAllPayload.ChannelID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnMessageReactionRemoveAllFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, channel, messageReactionRemoveAllPayload.MessageID))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnMessageReactionRemoveAllFuncType) error {
+		return f(eventCtx, channel, messageReactionRemoveAllPayload.MessageID)
+	})
 
 	return nil
 }
@@ -1479,14 +1791,9 @@ func OnMessageReactionRemoveEmoji(eventCtx *EventContext, payload sandwich_daemo
 
 	channel := NewChannel(messageReactionRemoveEmojiPayload.GuildID, messageReactionRemoveEmojiPayload.ChannelID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnMessageReactionRemoveEmojiFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, channel, messageReactionRemoveEmojiPayload.MessageID, messageReactionRemoveEmojiPayload.Emoji))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnMessageReactionRemoveEmojiFuncType) error {
+		return f(eventCtx, channel, messageReactionRemoveEmojiPayload.MessageID, messageReactionRemoveEmojiPayload.Emoji)
+	})
 
 	return nil
 }
@@ -1502,14 +1809,9 @@ func OnPresenceUpdate(eventCtx *EventContext, payload sandwich_daemon.ProducedPa
 
 	eventCtx.Guild = NewGuild(presenceUpdatePayload.GuildID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnPresenceUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, presenceUpdatePayload.User, presenceUpdatePayload))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnPresenceUpdateFuncType) error {
+		return f(eventCtx, presenceUpdatePayload.User, presenceUpdatePayload)
+	})
 
 	return nil
 }
@@ -1525,14 +1827,9 @@ func OnStageInstanceCreate(eventCtx *EventContext, payload sandwich_daemon.Produ
 
 	eventCtx.Guild = NewGuild(stageInstanceCreatePayload.GuildID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnStageInstanceCreateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.StageInstance(stageInstanceCreatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnStageInstanceCreateFuncType) error {
+		return f(eventCtx, discord.StageInstance(stageInstanceCreatePayload))
+	})
 
 	return nil
 }
@@ -1548,14 +1845,9 @@ func OnStageInstanceUpdate(eventCtx *EventContext, payload sandwich_daemon.Produ
 
 	eventCtx.Guild = NewGuild(stageInstanceUpdatePayload.GuildID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnStageInstanceUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.StageInstance(stageInstanceUpdatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnStageInstanceUpdateFuncType) error {
+		return f(eventCtx, discord.StageInstance(stageInstanceUpdatePayload))
+	})
 
 	return nil
 }
@@ -1569,14 +1861,9 @@ func OnStageInstanceDelete(eventCtx *EventContext, payload sandwich_daemon.Produ
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnStageInstanceDeleteFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.StageInstance(stageInstanceDeletePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnStageInstanceDeleteFuncType) error {
+		return f(eventCtx, discord.StageInstance(stageInstanceDeletePayload))
+	})
 
 	return nil
 }
@@ -1608,14 +1895,9 @@ func OnTypingStart(eventCtx *EventContext, payload sandwich_daemon.ProducedPaylo
 		user = NewUser(typingStartPayload.UserID)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnTypingStartFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, channel, member, user, timestamp))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnTypingStartFuncType) error {
+		return f(eventCtx, channel, member, user, timestamp)
+	})
 
 	return nil
 }
@@ -1634,14 +1916,9 @@ func OnUserUpdate(eventCtx *EventContext, payload sandwich_daemon.ProducedPayloa
 		return fmt.Errorf("failed to unmarshal extra: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnUserUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, beforeUser, discord.User(userUpdatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnUserUpdateFuncType) error {
+		return f(eventCtx, beforeUser, discord.User(userUpdatePayload))
+	})
 
 	return nil
 }
@@ -1657,7 +1934,10 @@ func OnVoiceStateUpdate(eventCtx *EventContext, payload sandwich_daemon.Produced
 
 	if voiceStateUpdatePayload.GuildID != nil {
 		eventCtx.Guild = NewGuild(*voiceStateUpdatePayload.GuildID)
-		voiceStateUpdatePayload.Member.GuildID = voiceStateUpdatePayload.GuildID
+
+		if voiceStateUpdatePayload.Member != nil {
+			voiceStateUpdatePayload.Member.GuildID = voiceStateUpdatePayload.GuildID
+		}
 	}
 
 	var beforeVoiceState discord.VoiceState
@@ -1670,14 +1950,9 @@ func OnVoiceStateUpdate(eventCtx *EventContext, payload sandwich_daemon.Produced
 		guildMember = *voiceStateUpdatePayload.Member
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnVoiceStateUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, guildMember, beforeVoiceState, discord.VoiceState(voiceStateUpdatePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnVoiceStateUpdateFuncType) error {
+		return f(eventCtx, guildMember, beforeVoiceState, discord.VoiceState(voiceStateUpdatePayload))
+	})
 
 	return nil
 }
@@ -1693,14 +1968,9 @@ func OnVoiceServerUpdate(eventCtx *EventContext, payload sandwich_daemon.Produce
 
 	eventCtx.Guild = NewGuild(voiceServerUpdatePayload.GuildID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnVoiceServerUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, voiceServerUpdatePayload))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnVoiceServerUpdateFuncType) error {
+		return f(eventCtx, voiceServerUpdatePayload)
+	})
 
 	return nil
 }
@@ -1718,14 +1988,9 @@ func OnWebhookUpdate(eventCtx *EventContext, payload sandwich_daemon.ProducedPay
 
 	channel := NewChannel(&webhookUpdatePayload.GuildID, webhookUpdatePayload.ChannelID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnWebhookUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, channel))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnWebhookUpdateFuncType) error {
+		return f(eventCtx, channel)
+	})
 
 	return nil
 }
@@ -1742,14 +2007,9 @@ func OnGuildJoin(eventCtx *EventContext, payload sandwich_daemon.ProducedPayload
 	guild := discord.Guild(guildCreatePayload)
 	eventCtx.Guild = &guild
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildJoinFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, guild))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildJoinFuncType) error {
+		return f(eventCtx, guild)
+	})
 
 	return nil
 }
@@ -1766,14 +2026,9 @@ func OnGuildAvailable(eventCtx *EventContext, payload sandwich_daemon.ProducedPa
 	guild := discord.Guild(guildCreatePayload)
 	eventCtx.Guild = &guild
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildJoinFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, guild))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildAvailableFuncType) error {
+		return f(eventCtx, guild)
+	})
 
 	return nil
 }
@@ -1797,14 +2052,9 @@ func OnGuildLeave(eventCtx *EventContext, payload sandwich_daemon.ProducedPayloa
 
 	eventCtx.Guild = &beforeGuild
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildLeaveFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, beforeGuild))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildLeaveFuncType) error {
+		return f(eventCtx, beforeGuild)
+	})
 
 	return nil
 }
@@ -1820,14 +2070,9 @@ func OnGuildUnavailable(eventCtx *EventContext, payload sandwich_daemon.Produced
 
 	eventCtx.Guild = NewGuild(guildDeletePayload.ID)
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnGuildUnavailableFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx, discord.UnavailableGuild(guildDeletePayload)))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnGuildUnavailableFuncType) error {
+		return f(eventCtx, discord.UnavailableGuild(guildDeletePayload))
+	})
 
 	return nil
 }
@@ -1838,14 +2083,9 @@ type OnGuildUnavailableFuncType func(eventCtx *EventContext, unavailableGuild di
 
 // OnSandwichConfigurationReload.
 func OnSandwichConfigurationReload(eventCtx *EventContext, _ sandwich_daemon.ProducedPayload) error {
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnSandwichConfigurationReloadFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(eventCtx))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnSandwichConfigurationReloadFuncType) error {
+		return f(eventCtx)
+	})
 
 	return nil
 }
@@ -1859,18 +2099,13 @@ func OnSandwichShardStatusUpdate(eventCtx *EventContext, payload sandwich_daemon
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
-
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnSandwichShardStatusUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(
-				eventCtx,
-				shardStatusUpdatePayload.Identifier,
-				shardStatusUpdatePayload.ShardID,
-				shardStatusUpdatePayload.Status))
-		}
-	}
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnSandwichShardStatusUpdateFuncType) error {
+		return f(
+			eventCtx,
+			shardStatusUpdatePayload.Identifier,
+			shardStatusUpdatePayload.ShardID,
+			shardStatusUpdatePayload.Status)
+	})
 
 	return nil
 }
@@ -1884,22 +2119,65 @@ func OnSandwichApplicationStatusUpdate(eventCtx *EventContext, payload sandwich_
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	eventCtx.EventHandler.EventsMu.RLock()
-	defer eventCtx.EventHandler.EventsMu.RUnlock()
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnSandwichApplicationStatusUpdateFuncType) error {
+		return f(
+			eventCtx,
+			applicationStatusUpdatePayload.Identifier,
+			applicationStatusUpdatePayload.Status)
+	})
+
+	return nil
+}
+
+type OnSandwichApplicationStatusUpdateFuncType func(eventCtx *EventContext, application string, status sandwich_daemon.ApplicationStatus) error
+
+// OnSandwichGRPCConnected.
+func OnSandwichGRPCConnected(eventCtx *EventContext, payload sandwich_daemon.ProducedPayload) error {
+	var connectedPayload GRPCConnectedEvent
+	if err := eventCtx.DecodeContent(payload, &connectedPayload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnSandwichGRPCConnectedFuncType) error {
+		return f(eventCtx, connectedPayload.Application)
+	})
 
-	for _, event := range eventCtx.EventHandler.Events {
-		if f, ok := event.(OnSandwichApplicationStatusUpdateFuncType); ok {
-			eventCtx.Handlers.WrapFuncType(eventCtx, f(
-				eventCtx,
-				applicationStatusUpdatePayload.Identifier,
-				applicationStatusUpdatePayload.Status))
-		}
+	return nil
+}
+
+type OnSandwichGRPCConnectedFuncType func(eventCtx *EventContext, application string) error
+
+// OnSandwichGRPCDisconnected.
+func OnSandwichGRPCDisconnected(eventCtx *EventContext, payload sandwich_daemon.ProducedPayload) error {
+	var disconnectedPayload GRPCDisconnectedEvent
+	if err := eventCtx.DecodeContent(payload, &disconnectedPayload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnSandwichGRPCDisconnectedFuncType) error {
+		return f(eventCtx, disconnectedPayload.Application, disconnectedPayload.Error)
+	})
+
 	return nil
 }
 
-type OnSandwichApplicationStatusUpdateFuncType func(eventCtx *EventContext, application string, status sandwich_daemon.ApplicationStatus) error
+type OnSandwichGRPCDisconnectedFuncType func(eventCtx *EventContext, application string, reason string) error
+
+// OnSandwichGRPCReconnecting.
+func OnSandwichGRPCReconnecting(eventCtx *EventContext, payload sandwich_daemon.ProducedPayload) error {
+	var reconnectingPayload GRPCReconnectingEvent
+	if err := eventCtx.DecodeContent(payload, &reconnectingPayload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	dispatchListeners(eventCtx, func(eventCtx *EventContext, f OnSandwichGRPCReconnectingFuncType) error {
+		return f(eventCtx, reconnectingPayload.Application, reconnectingPayload.Attempt, reconnectingPayload.Delay)
+	})
+
+	return nil
+}
+
+type OnSandwichGRPCReconnectingFuncType func(eventCtx *EventContext, application string, attempt int, delay time.Duration) error
 
 // Generic Events.
 
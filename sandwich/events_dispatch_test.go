@@ -0,0 +1,236 @@
+package internal_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// parserTest dispatches testdata/events/<name>.json and compares the arguments its listener
+// receives with testdata/events/<name>.golden.json.
+type parserTest struct {
+	name string
+
+	// grpc dispatches the payload to the SandwichEvents instead of the bot.
+	grpc bool
+
+	listen func(h *sandwich.Handlers, record func(args []any))
+}
+
+// listen registers a listener to an event that records the arguments it is called with.
+func listen[F any](event sandwich.Event[F]) func(h *sandwich.Handlers, record func(args []any)) {
+	return func(h *sandwich.Handlers, record func(args []any)) {
+		funcType := reflect.TypeFor[F]()
+
+		f := reflect.MakeFunc(funcType, func(values []reflect.Value) []reflect.Value {
+			// The first argument is the event context.
+			args := make([]any, 0, len(values)-1)
+			for _, value := range values[1:] {
+				args = append(args, value.Interface())
+			}
+
+			record(args)
+
+			return []reflect.Value{reflect.Zero(funcType.Out(0))}
+		})
+
+		sandwich.On(h, event, f.Interface().(F))
+	}
+}
+
+var parserTests = []parserTest{
+	{name: "READY", listen: listen(sandwich.EventReady)},
+	{name: "RESUMED", listen: listen(sandwich.EventResumed)},
+	{name: "APPLICATION_COMMAND_CREATE", listen: listen(sandwich.EventApplicationCommandCreate)},
+	{name: "APPLICATION_COMMAND_UPDATE", listen: listen(sandwich.EventApplicationCommandUpdate)},
+	{name: "APPLICATION_COMMAND_DELETE", listen: listen(sandwich.EventApplicationCommandDelete)},
+	{name: "CHANNEL_CREATE", listen: listen(sandwich.EventChannelCreate)},
+	{name: "CHANNEL_UPDATE", listen: listen(sandwich.EventChannelUpdate)},
+	{name: "CHANNEL_DELETE", listen: listen(sandwich.EventChannelDelete)},
+	{name: "CHANNEL_PINS_UPDATE", listen: listen(sandwich.EventChannelPinsUpdate)},
+	{name: "ENTITLEMENT_CREATE", listen: listen(sandwich.EventEntitlementCreate)},
+	{name: "ENTITLEMENT_UPDATE", listen: listen(sandwich.EventEntitlementUpdate)},
+	{name: "ENTITLEMENT_DELETE", listen: listen(sandwich.EventEntitlementDelete)},
+	{name: "THREAD_CREATE", listen: listen(sandwich.EventThreadCreate)},
+	{name: "THREAD_UPDATE", listen: listen(sandwich.EventThreadUpdate)},
+	{name: "THREAD_DELETE", listen: listen(sandwich.EventThreadDelete)},
+	{name: "THREAD_MEMBER_UPDATE", listen: listen(sandwich.EventThreadMemberUpdate)},
+	{name: "THREAD_MEMBERS_UPDATE", listen: listen(sandwich.EventThreadMembersUpdate)},
+	{name: "GUILD_UPDATE", listen: listen(sandwich.EventGuildUpdate)},
+	{name: "GUILD_AUDIT_LOG_ENTRY_CREATE", listen: listen(sandwich.EventGuildAuditLogEntryCreate)},
+	{name: "GUILD_BAN_ADD", listen: listen(sandwich.EventGuildBanAdd)},
+	{name: "GUILD_BAN_REMOVE", listen: listen(sandwich.EventGuildBanRemove)},
+	{name: "GUILD_EMOJIS_UPDATE", listen: listen(sandwich.EventGuildEmojisUpdate)},
+	{name: "GUILD_STICKERS_UPDATE", listen: listen(sandwich.EventGuildStickersUpdate)},
+	{name: "GUILD_INTEGRATIONS_UPDATE", listen: listen(sandwich.EventGuildIntegrationsUpdate)},
+	{name: "GUILD_MEMBER_ADD", listen: listen(sandwich.EventGuildMemberAdd)},
+	{name: "GUILD_MEMBER_REMOVE", listen: listen(sandwich.EventGuildMemberRemove)},
+	{name: "GUILD_MEMBER_UPDATE", listen: listen(sandwich.EventGuildMemberUpdate)},
+	{name: "GUILD_ROLE_CREATE", listen: listen(sandwich.EventGuildRoleCreate)},
+	{name: "GUILD_ROLE_UPDATE", listen: listen(sandwich.EventGuildRoleUpdate)},
+	{name: "GUILD_ROLE_DELETE", listen: listen(sandwich.EventGuildRoleDelete)},
+	{name: "INTEGRATION_CREATE", listen: listen(sandwich.EventIntegrationCreate)},
+	{name: "INTEGRATION_UPDATE", listen: listen(sandwich.EventIntegrationUpdate)},
+	{name: "INTEGRATION_DELETE", listen: listen(sandwich.EventIntegrationDelete)},
+	{name: "INTERACTION_CREATE", listen: listen(sandwich.EventInteractionCreate)},
+	{name: "INVITE_CREATE", listen: listen(sandwich.EventInviteCreate)},
+	{name: "INVITE_DELETE", listen: listen(sandwich.EventInviteDelete)},
+	{name: "MESSAGE_CREATE", listen: listen(sandwich.EventMessageCreate)},
+	{name: "MESSAGE_UPDATE", listen: listen(sandwich.EventMessageUpdate)},
+	{name: "MESSAGE_DELETE", listen: listen(sandwich.EventMessageDelete)},
+	{name: "MESSAGE_DELETE_BULK", listen: listen(sandwich.EventMessageDeleteBulk)},
+	{name: "MESSAGE_REACTION_ADD", listen: listen(sandwich.EventMessageReactionAdd)},
+	{name: "MESSAGE_REACTION_REMOVE", listen: listen(sandwich.EventMessageReactionRemove)},
+	{name: "MESSAGE_REACTION_REMOVE_ALL", listen: listen(sandwich.EventMessageReactionRemoveAll)},
+	{name: "MESSAGE_REACTION_REMOVE_EMOJI", listen: listen(sandwich.EventMessageReactionRemoveEmoji)},
+	{name: "PRESENCE_UPDATE", listen: listen(sandwich.EventPresenceUpdate)},
+	{name: "STAGE_INSTANCE_CREATE", listen: listen(sandwich.EventStageInstanceCreate)},
+	{name: "STAGE_INSTANCE_UPDATE", listen: listen(sandwich.EventStageInstanceUpdate)},
+	{name: "STAGE_INSTANCE_DELETE", listen: listen(sandwich.EventStageInstanceDelete)},
+	{name: "TYPING_START", listen: listen(sandwich.EventTypingStart)},
+	{name: "USER_UPDATE", listen: listen(sandwich.EventUserUpdate)},
+	{name: "VOICE_STATE_UPDATE", listen: listen(sandwich.EventVoiceStateUpdate)},
+	{name: "VOICE_SERVER_UPDATE", listen: listen(sandwich.EventVoiceServerUpdate)},
+	{name: "WEBHOOKS_UPDATE", listen: listen(sandwich.EventWebhookUpdate)},
+
+	// Custom events, dispatched by the GUILD_CREATE and GUILD_DELETE parsers.
+	{name: "GUILD_JOIN", listen: listen(sandwich.EventGuildJoin)},
+	{name: "GUILD_AVAILABLE", listen: listen(sandwich.EventGuildAvailable)},
+	{name: "GUILD_LEAVE", listen: listen(sandwich.EventGuildLeave)},
+	{name: "GUILD_UNAVAILABLE", listen: listen(sandwich.EventGuildUnavailable)},
+
+	{name: "SW_CONFIGURATION_RELOAD", grpc: true, listen: listen(sandwich.EventSandwichConfigurationReload)},
+	{name: "SW_SHARD_STATUS_UPDATE", grpc: true, listen: listen(sandwich.EventSandwichShardStatusUpdate)},
+	{name: "SW_APPLICATION_STATUS_UPDATE", grpc: true, listen: listen(sandwich.EventSandwichApplicationStatusUpdate)},
+	{name: "SW_GRPC_CONNECTED", grpc: true, listen: listen(sandwich.EventSandwichGRPCConnected)},
+	{name: "SW_GRPC_DISCONNECTED", grpc: true, listen: listen(sandwich.EventSandwichGRPCDisconnected)},
+	{name: "SW_GRPC_RECONNECTING", grpc: true, listen: listen(sandwich.EventSandwichGRPCReconnecting)},
+}
+
+func TestParsers(t *testing.T) {
+	for _, test := range parserTests {
+		t.Run(test.name, func(t *testing.T) {
+			harness := sandwichtest.New(t)
+
+			handlers := harness.Bot.Handlers
+			if test.grpc {
+				handlers = harness.Sandwich.SandwichEvents
+			}
+
+			var calls [][]any
+
+			test.listen(handlers, func(args []any) {
+				calls = append(calls, args)
+			})
+
+			payload := readPayload(t, filepath.Join("testdata", "events", test.name+".json"))
+
+			dispatch := harness.DispatchSync
+			if test.grpc {
+				dispatch = harness.DispatchGRPCSync
+			}
+
+			result, err := dispatch(payload)
+			if err != nil {
+				t.Fatalf("failed to dispatch: %v", err)
+			}
+
+			if err := result.Err(); err != nil {
+				t.Fatalf("failed to handle: %v", err)
+			}
+
+			if len(calls) != 1 {
+				t.Fatalf("expected the listener to be called once, got %d calls", len(calls))
+			}
+
+			for i, arg := range calls[0] {
+				// Times are compared in UTC so the golden files do not depend on the local time zone.
+				if timestamp, ok := arg.(time.Time); ok {
+					calls[0][i] = timestamp.UTC()
+				}
+			}
+
+			got, err := json.MarshalIndent(calls[0], "", "\t")
+			if err != nil {
+				t.Fatalf("failed to marshal arguments: %v", err)
+			}
+
+			compareGolden(t, filepath.Join("testdata", "events", test.name+".golden.json"), append(got, '\n'))
+		})
+	}
+}
+
+// TestParsersCovered makes sure every registered parser has a parser test.
+func TestParsersCovered(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	tested := make(map[string]bool)
+	for _, test := range parserTests {
+		tested[test.name] = true
+	}
+
+	// These parsers only dispatch the custom events.
+	tested["GUILD_CREATE"] = true
+	tested["GUILD_DELETE"] = true
+	tested[sandwich.DiscordEventError] = true
+
+	for _, handlers := range []*sandwich.Handlers{harness.Bot.Handlers, harness.Sandwich.SandwichEvents} {
+		for eventName := range handlers.EventHandlers {
+			if !tested[eventName] {
+				t.Errorf("no parser test for %s", eventName)
+			}
+		}
+	}
+}
+
+func readPayload(t *testing.T, path string) sandwich_daemon.ProducedPayload {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read payload: %v", err)
+	}
+
+	var payload sandwich_daemon.ProducedPayload
+
+	err = json.Unmarshal(data, &payload)
+	if err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+
+	return payload
+}
+
+// compareGolden compares got with a golden file, or updates the golden file if -update is set.
+func compareGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+
+	if *updateGolden {
+		err := os.WriteFile(path, got, 0o644)
+		if err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run with -update to create it: %v", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("arguments do not match %s, run with -update if the change is expected\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
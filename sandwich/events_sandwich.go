@@ -9,492 +9,519 @@ import (
 
 // RegisterOnReadyEvent adds a new event handler for the READY event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnReadyEvent(event OnReadyFuncType) {
+func (h *Handlers) RegisterOnReadyEvent(event OnReadyFuncType) *EventListener {
 	eventName := discord.DiscordEventReady
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnResumedEvent adds a new event handler for the RESUMED event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnResumedEvent(event OnResumedFuncType) {
+func (h *Handlers) RegisterOnResumedEvent(event OnResumedFuncType) *EventListener {
 	eventName := discord.DiscordEventResumed
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnApplicationCommandCreateEvent adds a new event handler for the APPLICATION_COMMAND_CREATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnApplicationCommandCreateEvent(event OnApplicationCommandCreateFuncType) {
+func (h *Handlers) RegisterOnApplicationCommandCreateEvent(event OnApplicationCommandCreateFuncType) *EventListener {
 	eventName := discord.DiscordEventApplicationCommandCreate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnApplicationCommandUpdateEvent adds a new event handler for the APPLICATION_COMMAND_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnApplicationCommandUpdateEvent(event OnApplicationCommandUpdateFuncType) {
+func (h *Handlers) RegisterOnApplicationCommandUpdateEvent(event OnApplicationCommandUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventApplicationCommandUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnApplicationCommandDeleteEvent adds a new event handler for the APPLICATION_COMMAND_DELETE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnApplicationCommandDeleteEvent(event OnApplicationCommandDeleteFuncType) {
+func (h *Handlers) RegisterOnApplicationCommandDeleteEvent(event OnApplicationCommandDeleteFuncType) *EventListener {
 	eventName := discord.DiscordEventApplicationCommandDelete
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnChannelCreateEvent adds a new event handler for the CHANNEL_CREATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnChannelCreateEvent(event OnChannelCreateFuncType) {
+func (h *Handlers) RegisterOnChannelCreateEvent(event OnChannelCreateFuncType) *EventListener {
 	eventName := discord.DiscordEventChannelCreate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnChannelUpdateEvent adds a new event handler for the CHANNEL_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnChannelUpdateEvent(event OnChannelUpdateFuncType) {
+func (h *Handlers) RegisterOnChannelUpdateEvent(event OnChannelUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventChannelUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnChannelDeleteEvent adds a new event handler for the CHANNEL_DELETE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnChannelDeleteEvent(event OnChannelDeleteFuncType) {
+func (h *Handlers) RegisterOnChannelDeleteEvent(event OnChannelDeleteFuncType) *EventListener {
 	eventName := discord.DiscordEventChannelDelete
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnChannelPinsUpdateEvent adds a new event handler for the CHANNEL_PINS_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnChannelPinsUpdateEvent(event OnChannelPinsUpdateFuncType) {
+func (h *Handlers) RegisterOnChannelPinsUpdateEvent(event OnChannelPinsUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventChannelPinsUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnEntitlementCreate adds a new event handler for the ENTITLEMENT_CREATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnEntitlementCreate(event OnEntitlementCreateFuncType) {
+func (h *Handlers) RegisterOnEntitlementCreate(event OnEntitlementCreateFuncType) *EventListener {
 	eventName := discord.DiscordEventEntitlementCreate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnEntitlementUpdate adds a new event handler for the ENTITLEMENT_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnEntitlementUpdate(event OnEntitlementCreateFuncType) {
+func (h *Handlers) RegisterOnEntitlementUpdate(event OnEntitlementUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventEntitlementUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnEntitlementDelete adds a new event handler for the ENTITLEMENT_DELETE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnEntitlementDelete(event OnEntitlementCreateFuncType) {
+func (h *Handlers) RegisterOnEntitlementDelete(event OnEntitlementDeleteFuncType) *EventListener {
 	eventName := discord.DiscordEventEntitlementDelete
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnThreadCreateEvent adds a new event handler for the THREAD_CREATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnThreadCreateEvent(event OnThreadCreateFuncType) {
+func (h *Handlers) RegisterOnThreadCreateEvent(event OnThreadCreateFuncType) *EventListener {
 	eventName := discord.DiscordEventThreadCreate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnThreadUpdateEvent adds a new event handler for the THREAD_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnThreadUpdateEvent(event OnThreadUpdateFuncType) {
+func (h *Handlers) RegisterOnThreadUpdateEvent(event OnThreadUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventThreadUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnThreadDeleteEvent adds a new event handler for the THREAD_DELETE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnThreadDeleteEvent(event OnThreadDeleteFuncType) {
+func (h *Handlers) RegisterOnThreadDeleteEvent(event OnThreadDeleteFuncType) *EventListener {
 	eventName := discord.DiscordEventThreadDelete
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnThreadMemberUpdateEvent adds a new event handler for the THREAD_MEMBER_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnThreadMemberUpdateEvent(event OnThreadMemberUpdateFuncType) {
+func (h *Handlers) RegisterOnThreadMemberUpdateEvent(event OnThreadMemberUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventThreadMemberUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnThreadMembersUpdateEvent adds a new event handler for the THREAD_MEMBERS_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnThreadMembersUpdateEvent(event OnThreadMembersUpdateFuncType) {
+func (h *Handlers) RegisterOnThreadMembersUpdateEvent(event OnThreadMembersUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventThreadMembersUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildUpdateEvent adds a new event handler for the GUILD_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildUpdateEvent(event OnGuildUpdateFuncType) {
+func (h *Handlers) RegisterOnGuildUpdateEvent(event OnGuildUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnAuditLogEntryCreateEvent adds a new event handler for the GUILD_AUDIT_LOG_ENTRY_CREATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnAuditGuildAuditLogEntryCreateEvent(event OnGuildAuditLogEntryCreateFuncType) {
+func (h *Handlers) RegisterOnAuditGuildAuditLogEntryCreateEvent(event OnGuildAuditLogEntryCreateFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildAuditLogEntryCreate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildBanAddEvent adds a new event handler for the GUILD_BAN_ADD event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildBanAddEvent(event OnGuildBanAddFuncType) {
+func (h *Handlers) RegisterOnGuildBanAddEvent(event OnGuildBanAddFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildBanAdd
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildBanRemoveEvent adds a new event handler for the GUILD_BAN_REMOVE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildBanRemoveEvent(event OnGuildBanRemoveFuncType) {
+func (h *Handlers) RegisterOnGuildBanRemoveEvent(event OnGuildBanRemoveFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildBanRemove
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildEmojisUpdateEvent adds a new event handler for the GUILD_EMOJIS_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildEmojisUpdateEvent(event OnGuildEmojisUpdateFuncType) {
+func (h *Handlers) RegisterOnGuildEmojisUpdateEvent(event OnGuildEmojisUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildEmojisUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildStickersUpdateEvent adds a new event handler for the GUILD_STICKERS_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildStickersUpdateEvent(event OnGuildStickersUpdateFuncType) {
+func (h *Handlers) RegisterOnGuildStickersUpdateEvent(event OnGuildStickersUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildStickersUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildIntegrationsUpdateEvent adds a new event handler for the GUILD_INTEGRATIONS_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildIntegrationsUpdateEvent(event OnGuildIntegrationsUpdateFuncType) {
+func (h *Handlers) RegisterOnGuildIntegrationsUpdateEvent(event OnGuildIntegrationsUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildIntegrationsUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildMemberAddEvent adds a new event handler for the GUILD_MEMBER_ADD event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildMemberAddEvent(event OnGuildMemberAddFuncType) {
+func (h *Handlers) RegisterOnGuildMemberAddEvent(event OnGuildMemberAddFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildMemberAdd
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildMemberRemoveEvent adds a new event handler for the GUILD_MEMBER_REMOVE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildMemberRemoveEvent(event OnGuildMemberRemoveFuncType) {
+func (h *Handlers) RegisterOnGuildMemberRemoveEvent(event OnGuildMemberRemoveFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildMemberRemove
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildMemberUpdateEvent adds a new event handler for the GUILD_MEMBER_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildMemberUpdateEvent(event OnGuildMemberUpdateFuncType) {
+func (h *Handlers) RegisterOnGuildMemberUpdateEvent(event OnGuildMemberUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildMemberUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildRoleCreateEvent adds a new event handler for the GUILD_ROLE_CREATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildRoleCreateEvent(event OnGuildRoleCreateFuncType) {
+func (h *Handlers) RegisterOnGuildRoleCreateEvent(event OnGuildRoleCreateFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildRoleCreate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildRoleUpdateEvent adds a new event handler for the GUILD_ROLE_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildRoleUpdateEvent(event OnGuildRoleUpdateFuncType) {
+func (h *Handlers) RegisterOnGuildRoleUpdateEvent(event OnGuildRoleUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildRoleUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildRoleDeleteEvent adds a new event handler for the GUILD_ROLE_DELETE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildRoleDeleteEvent(event OnGuildRoleDeleteFuncType) {
+func (h *Handlers) RegisterOnGuildRoleDeleteEvent(event OnGuildRoleDeleteFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildRoleDelete
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnIntegrationCreateEvent adds a new event handler for the INTEGRATION_CREATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnIntegrationCreateEvent(event OnIntegrationCreateFuncType) {
+func (h *Handlers) RegisterOnIntegrationCreateEvent(event OnIntegrationCreateFuncType) *EventListener {
 	eventName := discord.DiscordEventIntegrationCreate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnIntegrationUpdateEvent adds a new event handler for the INTEGRATION_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnIntegrationUpdateEvent(event OnIntegrationUpdateFuncType) {
+func (h *Handlers) RegisterOnIntegrationUpdateEvent(event OnIntegrationUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventIntegrationUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnIntegrationDeleteEvent adds a new event handler for the INTEGRATION_DELETE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnIntegrationDeleteEvent(event OnIntegrationDeleteFuncType) {
+func (h *Handlers) RegisterOnIntegrationDeleteEvent(event OnIntegrationDeleteFuncType) *EventListener {
 	eventName := discord.DiscordEventIntegrationDelete
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnInteractionCreateEvent adds a new event handler for the INTERACTION_CREATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnInteractionCreateEvent(event OnInteractionCreateFuncType) {
+func (h *Handlers) RegisterOnInteractionCreateEvent(event OnInteractionCreateFuncType) *EventListener {
 	eventName := discord.DiscordEventInteractionCreate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnInviteCreateEvent adds a new event handler for the INVITE_CREATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnInviteCreateEvent(event OnInviteCreateFuncType) {
+func (h *Handlers) RegisterOnInviteCreateEvent(event OnInviteCreateFuncType) *EventListener {
 	eventName := discord.DiscordEventInviteCreate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnInviteDeleteEvent adds a new event handler for the INVITE_DELETE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnInviteDeleteEvent(event OnInviteDeleteFuncType) {
+func (h *Handlers) RegisterOnInviteDeleteEvent(event OnInviteDeleteFuncType) *EventListener {
 	eventName := discord.DiscordEventInviteDelete
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnMessageCreateEvent adds a new event handler for the MESSAGE_CREATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnMessageCreateEvent(event OnMessageCreateFuncType) {
+func (h *Handlers) RegisterOnMessageCreateEvent(event OnMessageCreateFuncType) *EventListener {
 	eventName := discord.DiscordEventMessageCreate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnMessageUpdateEvent adds a new event handler for the MESSAGE_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnMessageUpdateEvent(event OnMessageUpdateFuncType) {
+func (h *Handlers) RegisterOnMessageUpdateEvent(event OnMessageUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventMessageUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnMessageDeleteEvent adds a new event handler for the MESSAGE_DELETE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnMessageDeleteEvent(event OnMessageDeleteFuncType) {
+func (h *Handlers) RegisterOnMessageDeleteEvent(event OnMessageDeleteFuncType) *EventListener {
 	eventName := discord.DiscordEventMessageDelete
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnMessageDeleteBulkEvent adds a new event handler for the MESSAGE_DELETE_BULK event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnMessageDeleteBulkEvent(event OnMessageDeleteBulkFuncType) {
+func (h *Handlers) RegisterOnMessageDeleteBulkEvent(event OnMessageDeleteBulkFuncType) *EventListener {
 	eventName := discord.DiscordEventMessageDeleteBulk
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnMessageReactionAddEvent adds a new event handler for the MESSAGE_REACTION_ADD event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnMessageReactionAddEvent(event OnMessageReactionAddFuncType) {
+func (h *Handlers) RegisterOnMessageReactionAddEvent(event OnMessageReactionAddFuncType) *EventListener {
 	eventName := discord.DiscordEventMessageReactionAdd
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnMessageReactionRemoveEvent adds a new event handler for the MESSAGE_REACTION_REMOVE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnMessageReactionRemoveEvent(event OnMessageReactionRemoveFuncType) {
+func (h *Handlers) RegisterOnMessageReactionRemoveEvent(event OnMessageReactionRemoveFuncType) *EventListener {
 	eventName := discord.DiscordEventMessageReactionRemove
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnMessageReactionRemoveAllEvent adds a new event handler for the MESSAGE_REACTION_REMOVE_ALL event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnMessageReactionRemoveAllEvent(event OnMessageReactionRemoveAllFuncType) {
+func (h *Handlers) RegisterOnMessageReactionRemoveAllEvent(event OnMessageReactionRemoveAllFuncType) *EventListener {
 	eventName := discord.DiscordEventMessageReactionRemoveAll
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnMessageReactionRemoveEmojiEvent adds a new event handler for the MESSAGE_REACTION_REMOVE_EMOJI event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnMessageReactionRemoveEmojiEvent(event OnMessageReactionRemoveEmojiFuncType) {
+func (h *Handlers) RegisterOnMessageReactionRemoveEmojiEvent(event OnMessageReactionRemoveEmojiFuncType) *EventListener {
 	eventName := discord.DiscordEventMessageReactionRemoveEmoji
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnPresenceUpdateEvent adds a new event handler for the PRESENCE_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnPresenceUpdateEvent(event OnPresenceUpdateFuncType) {
+func (h *Handlers) RegisterOnPresenceUpdateEvent(event OnPresenceUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventPresenceUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnStageInstanceCreateEvent adds a new event handler for the STAGE_INSTANCE_CREATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnStageInstanceCreateEvent(event OnStageInstanceCreateFuncType) {
+func (h *Handlers) RegisterOnStageInstanceCreateEvent(event OnStageInstanceCreateFuncType) *EventListener {
 	eventName := discord.DiscordEventStageInstanceCreate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnStageInstanceUpdateEvent adds a new event handler for the STAGE_INSTANCE_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnStageInstanceUpdateEvent(event OnStageInstanceUpdateFuncType) {
+func (h *Handlers) RegisterOnStageInstanceUpdateEvent(event OnStageInstanceUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventStageInstanceUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnStageInstanceDeleteEvent adds a new event handler for the STAGE_INSTANCE_DELETE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnStageInstanceDeleteEvent(event OnStageInstanceDeleteFuncType) {
+func (h *Handlers) RegisterOnStageInstanceDeleteEvent(event OnStageInstanceDeleteFuncType) *EventListener {
 	eventName := discord.DiscordEventStageInstanceDelete
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnTypingStartEvent adds a new event handler for the TYPING_START event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnTypingStartEvent(event OnTypingStartFuncType) {
+func (h *Handlers) RegisterOnTypingStartEvent(event OnTypingStartFuncType) *EventListener {
 	eventName := discord.DiscordEventTypingStart
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnUserUpdateEvent adds a new event handler for the USER_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnUserUpdateEvent(event OnUserUpdateFuncType) {
+func (h *Handlers) RegisterOnUserUpdateEvent(event OnUserUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventUserUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnVoiceStateUpdateEvent adds a new event handler for the VOICE_STATE_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnVoiceStateUpdateEvent(event OnVoiceStateUpdateFuncType) {
+func (h *Handlers) RegisterOnVoiceStateUpdateEvent(event OnVoiceStateUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventVoiceStateUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnVoiceServerUpdateEvent adds a new event handler for the VOICE_SERVER_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnVoiceServerUpdateEvent(event OnVoiceServerUpdateFuncType) {
+func (h *Handlers) RegisterOnVoiceServerUpdateEvent(event OnVoiceServerUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventVoiceServerUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnWebhookUpdateEvent adds a new event handler for the WEBHOOKS_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnWebhookUpdateEvent(event OnWebhookUpdateFuncType) {
+func (h *Handlers) RegisterOnWebhookUpdateEvent(event OnWebhookUpdateFuncType) *EventListener {
 	eventName := discord.DiscordEventWebhookUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildJoinEvent adds a new event handler for the GUILD_JOIN event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildJoinEvent(event OnGuildJoinFuncType) {
+func (h *Handlers) RegisterOnGuildJoinEvent(event OnGuildJoinFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildJoin
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildAvailableEvent adds a new event handler for the GUILD_AVAILABLE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildAvailableEvent(event OnGuildAvailableFuncType) {
+func (h *Handlers) RegisterOnGuildAvailableEvent(event OnGuildAvailableFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildAvailable
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildLeaveEvent adds a new event handler for the GUILD_LEAVE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildLeaveEvent(event OnGuildLeaveFuncType) {
+func (h *Handlers) RegisterOnGuildLeaveEvent(event OnGuildLeaveFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildLeave
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnGuildUnavailableEvent adds a new event handler for the GUILD_UNAVAILABLE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnGuildUnavailableEvent(event OnGuildUnavailableFuncType) {
+func (h *Handlers) RegisterOnGuildUnavailableEvent(event OnGuildUnavailableFuncType) *EventListener {
 	eventName := discord.DiscordEventGuildUnavailable
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // Sandwich Events.
 
 // RegisterOnSandwichConfigurationReload adds a new event handler for the SW_CONFIGURATION_RELOAD event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnSandwichConfigurationReload(event OnSandwichConfigurationReloadFuncType) {
+func (h *Handlers) RegisterOnSandwichConfigurationReload(event OnSandwichConfigurationReloadFuncType) *EventListener {
 	eventName := sandwich_daemon.SandwichEventConfigUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnSandwichShardStatusUpdate adds a new event handler for the SW_SHARD_STATUS_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnSandwichShardStatusUpdate(event OnSandwichShardStatusUpdateFuncType) {
+func (h *Handlers) RegisterOnSandwichShardStatusUpdate(event OnSandwichShardStatusUpdateFuncType) *EventListener {
 	eventName := sandwich_daemon.SandwichShardStatusUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
 
 // RegisterOnSandwichApplicationStatusUpdate adds a new event handler for the SW_APPLICATION_STATUS_UPDATE event.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnSandwichApplicationStatusUpdate(event OnSandwichApplicationStatusUpdateFuncType) {
+func (h *Handlers) RegisterOnSandwichApplicationStatusUpdate(event OnSandwichApplicationStatusUpdateFuncType) *EventListener {
 	eventName := sandwich_daemon.SandwichApplicationStatusUpdate
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
+}
+
+// RegisterOnSandwichGRPCConnected adds a new event handler for the SW_GRPC_CONNECTED event,
+// dispatched when the gRPC listener opens a stream.
+// It does not override a handler and instead will add another handler.
+func (h *Handlers) RegisterOnSandwichGRPCConnected(event OnSandwichGRPCConnectedFuncType) *EventListener {
+	eventName := SandwichEventGRPCConnected
+
+	return h.RegisterEventListener(eventName, event)
+}
+
+// RegisterOnSandwichGRPCDisconnected adds a new event handler for the SW_GRPC_DISCONNECTED event,
+// dispatched when the stream of the gRPC listener fails.
+// It does not override a handler and instead will add another handler.
+func (h *Handlers) RegisterOnSandwichGRPCDisconnected(event OnSandwichGRPCDisconnectedFuncType) *EventListener {
+	eventName := SandwichEventGRPCDisconnected
+
+	return h.RegisterEventListener(eventName, event)
+}
+
+// RegisterOnSandwichGRPCReconnecting adds a new event handler for the SW_GRPC_RECONNECTING event,
+// dispatched when the gRPC listener waits before reconnecting.
+// It does not override a handler and instead will add another handler.
+func (h *Handlers) RegisterOnSandwichGRPCReconnecting(event OnSandwichGRPCReconnectingFuncType) *EventListener {
+	eventName := SandwichEventGRPCReconnecting
+
+	return h.RegisterEventListener(eventName, event)
 }
 
 // Generic Events.
 
 // RegisterOnError registers a handler when events raise an error.
 // It does not override a handler and instead will add another handler.
-func (h *Handlers) RegisterOnError(event OnErrorFuncType) {
-	eventName := "ERROR"
+func (h *Handlers) RegisterOnError(event OnErrorFuncType) *EventListener {
+	eventName := DiscordEventError
 
-	h.RegisterEvent(eventName, nil, event)
+	return h.RegisterEventListener(eventName, event)
 }
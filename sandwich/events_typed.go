@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	discord "github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+)
+
+// Event describes an event by its name and the type of func its listeners must be.
+type Event[F any] struct {
+	Name string
+}
+
+var (
+	eventFuncTypesMu sync.RWMutex
+	eventFuncTypes   = make(map[string]reflect.Type)
+)
+
+// NewEvent creates a new event descriptor. Funcs registered to the event through any
+// registration method must then be of type F, or have the same signature as F.
+// Listeners can only be registered to events that have a descriptor, so custom events
+// must be created with NewEvent. Panics if an event with the name already exists.
+func NewEvent[F any](eventName string) Event[F] {
+	eventFuncTypesMu.Lock()
+	defer eventFuncTypesMu.Unlock()
+
+	if funcType, ok := eventFuncTypes[eventName]; ok {
+		panic(fmt.Sprintf(`sandwich: NewEvent(%s): event already exists with type %s`, eventName, funcType))
+	}
+
+	eventFuncTypes[eventName] = reflect.TypeFor[F]()
+
+	return Event[F]{Name: eventName}
+}
+
+// On adds a new listener to an event. As the func must be of the type of the event,
+// registering a func with the wrong signature fails to compile.
+func On[F any](h *Handlers, event Event[F], f F) *EventListener {
+	return h.RegisterEventListener(event.Name, f)
+}
+
+// validateEventListener checks a listener can be called by the parser of an event.
+// Funcs with the same signature as the type of the event are converted to it. Events
+// without a descriptor, such as GUILD_CREATE which is dispatched as GUILD_JOIN or
+// GUILD_AVAILABLE, never call their listeners.
+func validateEventListener(eventName string, listener *EventListener) error {
+	eventFuncTypesMu.RLock()
+	funcType, ok := eventFuncTypes[eventName]
+	eventFuncTypesMu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("%w: no listeners are called for %s", ErrInvalidEventFunc, eventName)
+	}
+
+	value := reflect.ValueOf(listener.Func)
+
+	switch {
+	case !value.IsValid():
+		return fmt.Errorf("%w: expected %s, got nil", ErrInvalidEventFunc, funcType)
+	case value.Type() == funcType:
+		return nil
+	case value.Type().ConvertibleTo(funcType):
+		listener.Func = value.Convert(funcType).Interface()
+
+		return nil
+	default:
+		return fmt.Errorf("%w: expected %s, got %s", ErrInvalidEventFunc, funcType, value.Type())
+	}
+}
+
+// Discord Events.
+
+var (
+	EventReady                      = NewEvent[OnReadyFuncType](discord.DiscordEventReady)
+	EventResumed                    = NewEvent[OnResumedFuncType](discord.DiscordEventResumed)
+	EventApplicationCommandCreate   = NewEvent[OnApplicationCommandCreateFuncType](discord.DiscordEventApplicationCommandCreate)
+	EventApplicationCommandUpdate   = NewEvent[OnApplicationCommandUpdateFuncType](discord.DiscordEventApplicationCommandUpdate)
+	EventApplicationCommandDelete   = NewEvent[OnApplicationCommandDeleteFuncType](discord.DiscordEventApplicationCommandDelete)
+	EventChannelCreate              = NewEvent[OnChannelCreateFuncType](discord.DiscordEventChannelCreate)
+	EventChannelUpdate              = NewEvent[OnChannelUpdateFuncType](discord.DiscordEventChannelUpdate)
+	EventChannelDelete              = NewEvent[OnChannelDeleteFuncType](discord.DiscordEventChannelDelete)
+	EventChannelPinsUpdate          = NewEvent[OnChannelPinsUpdateFuncType](discord.DiscordEventChannelPinsUpdate)
+	EventEntitlementCreate          = NewEvent[OnEntitlementCreateFuncType](discord.DiscordEventEntitlementCreate)
+	EventEntitlementUpdate          = NewEvent[OnEntitlementUpdateFuncType](discord.DiscordEventEntitlementUpdate)
+	EventEntitlementDelete          = NewEvent[OnEntitlementDeleteFuncType](discord.DiscordEventEntitlementDelete)
+	EventThreadCreate               = NewEvent[OnThreadCreateFuncType](discord.DiscordEventThreadCreate)
+	EventThreadUpdate               = NewEvent[OnThreadUpdateFuncType](discord.DiscordEventThreadUpdate)
+	EventThreadDelete               = NewEvent[OnThreadDeleteFuncType](discord.DiscordEventThreadDelete)
+	EventThreadMemberUpdate         = NewEvent[OnThreadMemberUpdateFuncType](discord.DiscordEventThreadMemberUpdate)
+	EventThreadMembersUpdate        = NewEvent[OnThreadMembersUpdateFuncType](discord.DiscordEventThreadMembersUpdate)
+	EventGuildUpdate                = NewEvent[OnGuildUpdateFuncType](discord.DiscordEventGuildUpdate)
+	EventGuildAuditLogEntryCreate   = NewEvent[OnGuildAuditLogEntryCreateFuncType](discord.DiscordEventGuildAuditLogEntryCreate)
+	EventGuildBanAdd                = NewEvent[OnGuildBanAddFuncType](discord.DiscordEventGuildBanAdd)
+	EventGuildBanRemove             = NewEvent[OnGuildBanRemoveFuncType](discord.DiscordEventGuildBanRemove)
+	EventGuildEmojisUpdate          = NewEvent[OnGuildEmojisUpdateFuncType](discord.DiscordEventGuildEmojisUpdate)
+	EventGuildStickersUpdate        = NewEvent[OnGuildStickersUpdateFuncType](discord.DiscordEventGuildStickersUpdate)
+	EventGuildIntegrationsUpdate    = NewEvent[OnGuildIntegrationsUpdateFuncType](discord.DiscordEventGuildIntegrationsUpdate)
+	EventGuildMemberAdd             = NewEvent[OnGuildMemberAddFuncType](discord.DiscordEventGuildMemberAdd)
+	EventGuildMemberRemove          = NewEvent[OnGuildMemberRemoveFuncType](discord.DiscordEventGuildMemberRemove)
+	EventGuildMemberUpdate          = NewEvent[OnGuildMemberUpdateFuncType](discord.DiscordEventGuildMemberUpdate)
+	EventGuildRoleCreate            = NewEvent[OnGuildRoleCreateFuncType](discord.DiscordEventGuildRoleCreate)
+	EventGuildRoleUpdate            = NewEvent[OnGuildRoleUpdateFuncType](discord.DiscordEventGuildRoleUpdate)
+	EventGuildRoleDelete            = NewEvent[OnGuildRoleDeleteFuncType](discord.DiscordEventGuildRoleDelete)
+	EventIntegrationCreate          = NewEvent[OnIntegrationCreateFuncType](discord.DiscordEventIntegrationCreate)
+	EventIntegrationUpdate          = NewEvent[OnIntegrationUpdateFuncType](discord.DiscordEventIntegrationUpdate)
+	EventIntegrationDelete          = NewEvent[OnIntegrationDeleteFuncType](discord.DiscordEventIntegrationDelete)
+	EventInteractionCreate          = NewEvent[OnInteractionCreateFuncType](discord.DiscordEventInteractionCreate)
+	EventInviteCreate               = NewEvent[OnInviteCreateFuncType](discord.DiscordEventInviteCreate)
+	EventInviteDelete               = NewEvent[OnInviteDeleteFuncType](discord.DiscordEventInviteDelete)
+	EventMessageCreate              = NewEvent[OnMessageCreateFuncType](discord.DiscordEventMessageCreate)
+	EventMessageUpdate              = NewEvent[OnMessageUpdateFuncType](discord.DiscordEventMessageUpdate)
+	EventMessageDelete              = NewEvent[OnMessageDeleteFuncType](discord.DiscordEventMessageDelete)
+	EventMessageDeleteBulk          = NewEvent[OnMessageDeleteBulkFuncType](discord.DiscordEventMessageDeleteBulk)
+	EventMessageReactionAdd         = NewEvent[OnMessageReactionAddFuncType](discord.DiscordEventMessageReactionAdd)
+	EventMessageReactionRemove      = NewEvent[OnMessageReactionRemoveFuncType](discord.DiscordEventMessageReactionRemove)
+	EventMessageReactionRemoveAll   = NewEvent[OnMessageReactionRemoveAllFuncType](discord.DiscordEventMessageReactionRemoveAll)
+	EventMessageReactionRemoveEmoji = NewEvent[OnMessageReactionRemoveEmojiFuncType](discord.DiscordEventMessageReactionRemoveEmoji)
+	EventPresenceUpdate             = NewEvent[OnPresenceUpdateFuncType](discord.DiscordEventPresenceUpdate)
+	EventStageInstanceCreate        = NewEvent[OnStageInstanceCreateFuncType](discord.DiscordEventStageInstanceCreate)
+	EventStageInstanceUpdate        = NewEvent[OnStageInstanceUpdateFuncType](discord.DiscordEventStageInstanceUpdate)
+	EventStageInstanceDelete        = NewEvent[OnStageInstanceDeleteFuncType](discord.DiscordEventStageInstanceDelete)
+	EventTypingStart                = NewEvent[OnTypingStartFuncType](discord.DiscordEventTypingStart)
+	EventUserUpdate                 = NewEvent[OnUserUpdateFuncType](discord.DiscordEventUserUpdate)
+	EventVoiceStateUpdate           = NewEvent[OnVoiceStateUpdateFuncType](discord.DiscordEventVoiceStateUpdate)
+	EventVoiceServerUpdate          = NewEvent[OnVoiceServerUpdateFuncType](discord.DiscordEventVoiceServerUpdate)
+	EventWebhookUpdate              = NewEvent[OnWebhookUpdateFuncType](discord.DiscordEventWebhookUpdate)
+)
+
+// Custom Events.
+
+var (
+	EventGuildJoin        = NewEvent[OnGuildJoinFuncType](discord.DiscordEventGuildJoin)
+	EventGuildAvailable   = NewEvent[OnGuildAvailableFuncType](discord.DiscordEventGuildAvailable)
+	EventGuildLeave       = NewEvent[OnGuildLeaveFuncType](discord.DiscordEventGuildLeave)
+	EventGuildUnavailable = NewEvent[OnGuildUnavailableFuncType](discord.DiscordEventGuildUnavailable)
+)
+
+// Sandwich Events.
+
+var (
+	EventSandwichConfigurationReload     = NewEvent[OnSandwichConfigurationReloadFuncType](sandwich_daemon.SandwichEventConfigUpdate)
+	EventSandwichShardStatusUpdate       = NewEvent[OnSandwichShardStatusUpdateFuncType](sandwich_daemon.SandwichShardStatusUpdate)
+	EventSandwichApplicationStatusUpdate = NewEvent[OnSandwichApplicationStatusUpdateFuncType](sandwich_daemon.SandwichApplicationStatusUpdate)
+	EventSandwichGRPCConnected           = NewEvent[OnSandwichGRPCConnectedFuncType](SandwichEventGRPCConnected)
+	EventSandwichGRPCDisconnected        = NewEvent[OnSandwichGRPCDisconnectedFuncType](SandwichEventGRPCDisconnected)
+	EventSandwichGRPCReconnecting        = NewEvent[OnSandwichGRPCReconnectingFuncType](SandwichEventGRPCReconnecting)
+)
+
+// Generic Events.
+
+var (
+	EventError = NewEvent[OnErrorFuncType](DiscordEventError)
+)
@@ -0,0 +1,137 @@
+package internal_test
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+func TestRegisterEventListenerValidates(t *testing.T) {
+	called := make(chan struct{}, 1)
+
+	tests := []struct {
+		name      string
+		event     string
+		f         any
+		wantPanic bool
+	}{
+		{
+			name: "event type",
+			f: sandwich.OnResumedFuncType(func(*sandwich.EventContext) error {
+				called <- struct{}{}
+
+				return nil
+			}),
+		},
+		{
+			name: "same signature",
+			f: func(*sandwich.EventContext) error {
+				called <- struct{}{}
+
+				return nil
+			},
+		},
+		{
+			name:      "wrong signature",
+			f:         func(*sandwich.EventContext, discord.Message) error { return nil },
+			wantPanic: true,
+		},
+		{
+			name:      "nil",
+			f:         nil,
+			wantPanic: true,
+		},
+		{
+			name:      "unknown event",
+			event:     "RESUMD",
+			f:         func(*sandwich.EventContext) error { return nil },
+			wantPanic: true,
+		},
+		{
+			name:      "event without listeners",
+			event:     discord.DiscordEventGuildCreate,
+			f:         func(*sandwich.EventContext) error { return nil },
+			wantPanic: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sandwichClient, bot := newTestBot()
+
+			panicked := func() (panicked bool) {
+				defer func() {
+					if errorValue := recover(); errorValue != nil {
+						panicked = true
+
+						if !strings.Contains(errorValue.(string), sandwich.ErrInvalidEventFunc.Error()) {
+							t.Errorf("panicked with %v, want %v", errorValue, sandwich.ErrInvalidEventFunc)
+						}
+					}
+				}()
+
+				event := test.event
+				if event == "" {
+					event = discord.DiscordEventResumed
+				}
+
+				bot.Handlers.RegisterEventListener(event, test.f)
+
+				return false
+			}()
+
+			if panicked != test.wantPanic {
+				t.Fatalf("registering panicked: %v, want %v", panicked, test.wantPanic)
+			}
+
+			if panicked {
+				return
+			}
+
+			dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+			select {
+			case <-called:
+			case <-time.After(time.Second * 5):
+				t.Error("listener was not called")
+			}
+		})
+	}
+}
+
+// customEvents counts the events created by TestNewEvent.
+var customEvents atomic.Int32
+
+func TestNewEvent(t *testing.T) {
+	type onCustomFuncType func(eventCtx *sandwich.EventContext, value string) error
+
+	// Events can only be created once, so every run of the test creates a new one.
+	event := sandwich.NewEvent[onCustomFuncType](fmt.Sprintf("TEST_CUSTOM_EVENT_%d", customEvents.Add(1)))
+
+	_, bot := newTestBot()
+
+	sandwich.On(bot.Handlers, event, func(*sandwich.EventContext, string) error { return nil })
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registered a func of the wrong type to a custom event")
+		}
+	}()
+
+	bot.Handlers.RegisterEventListener(event.Name, func(*sandwich.EventContext) error { return nil })
+}
+
+func TestNewEventExists(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("created an event that already exists")
+		}
+	}()
+
+	sandwich.NewEvent[sandwich.OnResumedFuncType](discord.DiscordEventResumed)
+}
@@ -0,0 +1,24 @@
+package internal
+
+// FairLimiter exposes fairLimiter to the tests in internal_test.
+type FairLimiter = fairLimiter
+
+var NewFairLimiter = newFairLimiter
+
+var QueueCapacityFromEnv = queueCapacityFromEnv
+
+// Waiting returns the number of dispatches of an application waiting for a slot.
+func (l *fairLimiter) Waiting(application string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.waiting[application])
+}
+
+// RetriesStopped returns true once Close has stopped accepting listener retries.
+func (h *Handlers) RetriesStopped() bool {
+	h.retriesMu.Lock()
+	defer h.retriesMu.Unlock()
+
+	return h.retriesStopped
+}
@@ -0,0 +1,92 @@
+package internal
+
+import "sync"
+
+// fairLimiter limits the number of concurrent dispatches across all applications.
+// When there are more dispatches than slots, freed slots are handed to the waiting
+// application with the fewest active dispatches so one busy application cannot
+// starve the rest.
+type fairLimiter struct {
+	mu sync.Mutex
+
+	limit int
+	total int
+
+	active  map[string]int
+	waiting map[string][]chan struct{}
+
+	// order holds applications that have waiters, in the order they started waiting.
+	order []string
+}
+
+func newFairLimiter(limit int) *fairLimiter {
+	return &fairLimiter{
+		mu:      sync.Mutex{},
+		limit:   limit,
+		active:  make(map[string]int),
+		waiting: make(map[string][]chan struct{}),
+		order:   make([]string, 0),
+	}
+}
+
+// Acquire blocks until the application can start a dispatch.
+func (l *fairLimiter) Acquire(application string) {
+	l.mu.Lock()
+
+	if l.total < l.limit {
+		l.active[application]++
+		l.total++
+		l.mu.Unlock()
+
+		return
+	}
+
+	ready := make(chan struct{})
+
+	if len(l.waiting[application]) == 0 {
+		l.order = append(l.order, application)
+	}
+
+	l.waiting[application] = append(l.waiting[application], ready)
+	l.mu.Unlock()
+
+	<-ready
+}
+
+// Release frees a slot previously acquired by the application.
+func (l *fairLimiter) Release(application string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.total--
+
+	if l.active[application]--; l.active[application] <= 0 {
+		delete(l.active, application)
+	}
+
+	for l.total < l.limit && len(l.order) > 0 {
+		next := 0
+
+		for i, waitingApplication := range l.order {
+			if l.active[waitingApplication] < l.active[l.order[next]] {
+				next = i
+			}
+		}
+
+		nextApplication := l.order[next]
+		l.order = append(l.order[:next], l.order[next+1:]...)
+
+		ready := l.waiting[nextApplication][0]
+
+		if l.waiting[nextApplication] = l.waiting[nextApplication][1:]; len(l.waiting[nextApplication]) > 0 {
+			l.order = append(l.order, nextApplication)
+		} else {
+			delete(l.waiting, nextApplication)
+		}
+
+		l.active[nextApplication]++
+		l.total++
+
+		close(ready)
+	}
+}
@@ -0,0 +1,94 @@
+package internal_test
+
+import (
+	"testing"
+	"time"
+
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+// acquire acquires a slot of the limiter in the background, closing the returned
+// channel once it has been acquired. Returns once the acquire is waiting for a slot.
+func acquire(t *testing.T, limiter *sandwich.FairLimiter, application string) <-chan struct{} {
+	t.Helper()
+
+	waiting := limiter.Waiting(application)
+	acquired := make(chan struct{})
+
+	go func() {
+		limiter.Acquire(application)
+		close(acquired)
+	}()
+
+	deadline := time.Now().Add(time.Second * 5)
+	for limiter.Waiting(application) == waiting {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s did not wait for a slot", application)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	return acquired
+}
+
+func isAcquired(acquired <-chan struct{}) bool {
+	select {
+	case <-acquired:
+		return true
+	case <-time.After(time.Millisecond * 50):
+		return false
+	}
+}
+
+func TestFairLimiterPrefersLeastActiveApplication(t *testing.T) {
+	limiter := sandwich.NewFairLimiter(2)
+
+	limiter.Acquire("a")
+	limiter.Acquire("a")
+
+	// a starts waiting first but b has no active dispatches, so b is given the next slot.
+	acquiredA := acquire(t, limiter, "a")
+	acquiredB := acquire(t, limiter, "b")
+
+	limiter.Release("a")
+
+	if !isAcquired(acquiredB) {
+		t.Fatal("b was not given the released slot")
+	}
+
+	if isAcquired(acquiredA) {
+		t.Fatal("a was given a slot whilst the limit was reached")
+	}
+
+	limiter.Release("b")
+
+	if !isAcquired(acquiredA) {
+		t.Fatal("a was not given the released slot")
+	}
+}
+
+func TestFairLimiterKeepsWaitingOrder(t *testing.T) {
+	limiter := sandwich.NewFairLimiter(1)
+
+	limiter.Acquire("a")
+
+	first := acquire(t, limiter, "b")
+	second := acquire(t, limiter, "b")
+
+	limiter.Release("a")
+
+	if !isAcquired(first) {
+		t.Fatal("the first waiting dispatch was not given the released slot")
+	}
+
+	if isAcquired(second) {
+		t.Fatal("the second waiting dispatch was given a slot whilst the limit was reached")
+	}
+
+	limiter.Release("b")
+
+	if !isAcquired(second) {
+		t.Fatal("the second waiting dispatch was not given the released slot")
+	}
+}
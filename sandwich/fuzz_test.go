@@ -0,0 +1,74 @@
+package internal_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+)
+
+// FuzzDispatchProducedPayload dispatches arbitrary messages, as received from a message
+// queue, to a bot listening to every event and fails if the parser or a listener panics.
+func FuzzDispatchProducedPayload(f *testing.F) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "events", "*.json"))
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, ".golden.json") {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(data)
+	}
+
+	f.Add([]byte(`{"op":0,"t":"VOICE_STATE_UPDATE","d":{"guild_id":"1"},"__metadata":{"i":"test","a":"test"}}`))
+	f.Add([]byte(`{"op":0,"t":"GUILD_MEMBER_ADD","d":{},"__metadata":{"i":"test","a":"test"}}`))
+	f.Add([]byte(`{"op":0,"t":"THREAD_MEMBERS_UPDATE","d":{"added_members":[{}]},"__metadata":{"i":"test","a":"test"}}`))
+	f.Add([]byte(`{"op":0,"t":"CHANNEL_UPDATE","d":null,"__extra":{"before":"x"},"__metadata":{"i":"test","a":"test"}}`))
+
+	f.Fuzz(func(t *testing.T, message []byte) {
+		harness := sandwichtest.New(t)
+
+		for _, test := range parserTests {
+			handlers := harness.Bot.Handlers
+			if test.grpc {
+				handlers = harness.Sandwich.SandwichEvents
+			}
+
+			test.listen(handlers, func([]any) {})
+		}
+
+		var payload sandwich_daemon.ProducedPayload
+
+		// Messages that are not payloads are dead lettered before being dispatched.
+		if json.Unmarshal(message, &payload) != nil {
+			return
+		}
+
+		for _, dispatch := range []func(sandwich_daemon.ProducedPayload) (*sandwich.DispatchResult, error){
+			harness.DispatchSync,
+			harness.DispatchGRPCSync,
+		} {
+			result, err := dispatch(payload)
+			if err != nil {
+				t.Fatalf("failed to dispatch: %v", err)
+			}
+
+			for _, panicErr := range result.Panics() {
+				t.Fatalf("%s panicked: %v\n%s", payload.Type, panicErr.Value, panicErr.Stack)
+			}
+		}
+	})
+}
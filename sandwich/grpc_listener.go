@@ -0,0 +1,284 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	discord "github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich_protobuf "github.com/WelcomerTeam/Sandwich-Daemon/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultReconnectBackoff    = time.Second
+	defaultReconnectMaxBackoff = time.Second * 30
+)
+
+// GRPCConnectedEvent is the payload of the SW_GRPC_CONNECTED event. Application is
+// empty if the stream receives events of every application.
+type GRPCConnectedEvent struct {
+	Application string `json:"application"`
+}
+
+// GRPCDisconnectedEvent is the payload of the SW_GRPC_DISCONNECTED event.
+type GRPCDisconnectedEvent struct {
+	Application string `json:"application"`
+	Error       string `json:"error"`
+}
+
+// GRPCReconnectingEvent is the payload of the SW_GRPC_RECONNECTING event.
+type GRPCReconnectingEvent struct {
+	Application string        `json:"application"`
+	Attempt     int           `json:"attempt"`
+	Delay       time.Duration `json:"delay"`
+}
+
+// grpcSubscription is a gRPC stream opened by ListenToChannel.
+type grpcSubscription struct {
+	// application is the application the stream receives events of, or empty for every application.
+	application string
+
+	// primary streams receive the events that do not belong to an application.
+	primary bool
+}
+
+// grpcMessage is a message received from the stream of a subscription.
+type grpcMessage struct {
+	response     *sandwich_protobuf.ListenResponse
+	subscription *grpcSubscription
+}
+
+// WithReconnectBackoff sets how long the gRPC listener waits before reconnecting. The wait
+// starts at initial and doubles after every failed attempt, with jitter, up to max.
+// Defaults to 1 second, up to 30 seconds.
+func WithReconnectBackoff(initial, max time.Duration) ListenOption {
+	return func(options *listenOptions) {
+		options.reconnectBackoff = initial
+		options.reconnectMaxBackoff = max
+	}
+}
+
+// WithIdleTimeout reconnects the gRPC listener if it does not receive a message within
+// the timeout, as a stalled stream is otherwise indistinguishable from a quiet one.
+// The timeout should be longer than the longest expected gap between events.
+// A value of 0 or less disables it, which is the default.
+func WithIdleTimeout(timeout time.Duration) ListenOption {
+	return func(options *listenOptions) {
+		options.idleTimeout = timeout
+	}
+}
+
+// WithGRPCApplications only receives events of the given applications from gRPC, opening
+// a stream for each of them, and dispatches their Discord events to the bots. Sandwich
+// events that do not belong to an application, such as SW_CONFIGURATION_RELOAD, are still
+// received. By default events of every application are received on a single stream and
+// only Sandwich events are dispatched.
+func WithGRPCApplications(applications ...string) ListenOption {
+	return func(options *listenOptions) {
+		options.grpcApplications = applications
+		options.routeGRPCEvents = true
+	}
+}
+
+// WithGRPCEvents dispatches the given Discord event types received from gRPC to the bots.
+// Sandwich events are always dispatched, as the Sandwich relies on them. By default only
+// Sandwich events are dispatched. With WithGRPCApplications and no WithGRPCEvents, every
+// Discord event of the applications is dispatched.
+func WithGRPCEvents(eventTypes ...string) ListenOption {
+	return func(options *listenOptions) {
+		options.routeGRPCEvents = true
+		options.grpcEvents = make(map[string]bool, len(eventTypes))
+		for _, eventType := range eventTypes {
+			options.grpcEvents[eventType] = true
+		}
+	}
+}
+
+// grpcSubscriptions returns the gRPC streams to open.
+func (options *listenOptions) grpcSubscriptions() []*grpcSubscription {
+	if len(options.grpcApplications) == 0 {
+		return []*grpcSubscription{{application: "", primary: true}}
+	}
+
+	subscriptions := make([]*grpcSubscription, 0, len(options.grpcApplications))
+	for i, application := range slices.Compact(slices.Sorted(slices.Values(options.grpcApplications))) {
+		subscriptions = append(subscriptions, &grpcSubscription{
+			application: application,
+			primary:     i == 0,
+		})
+	}
+
+	return subscriptions
+}
+
+// acceptGRPCPayload returns true if a payload received from the stream of a subscription
+// should be dispatched. Sandwich may send every event on every stream, so events of other
+// applications are dropped and events without an application are only taken from the primary stream.
+// Discord events are only dispatched if WithGRPCApplications or WithGRPCEvents was used.
+func (sandwich *Sandwich) acceptGRPCPayload(options *listenOptions, subscription *grpcSubscription, payload *sandwich_daemon.ProducedPayload) bool {
+	if subscription.application != "" && payload.Metadata.Application != subscription.application {
+		if payload.Metadata.Application != "" || !subscription.primary {
+			return false
+		}
+	}
+
+	if sandwich.SandwichEvents.hasEventHandler(payload.Type) {
+		return true
+	}
+
+	if !options.routeGRPCEvents {
+		return false
+	}
+
+	return options.grpcEvents == nil || options.grpcEvents[payload.Type]
+}
+
+// listenGRPC sends messages from the gRPC listener to messages, reconnecting with backoff
+// if the stream fails. Events about the state of the listener are dispatched with dispatchCtx.
+// Returns nil once the context is done, or an error if the listener cannot be recovered.
+func (sandwich *Sandwich) listenGRPC(ctx, dispatchCtx context.Context, options *listenOptions, subscription *grpcSubscription, messages chan<- grpcMessage) error {
+	backoff := NewRetryPolicy(0, options.reconnectBackoff, options.reconnectMaxBackoff)
+
+	var attempt int
+
+	for {
+		received, err := sandwich.receiveGRPC(ctx, dispatchCtx, options.idleTimeout, subscription, messages)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if isFatalGRPCError(err) {
+			return err
+		}
+
+		sandwich.Logger.Warn("Grpc listener disconnected", "application", subscription.application, "error", err)
+
+		// Only back off further whilst the listener keeps failing.
+		if received {
+			attempt = 0
+		}
+
+		attempt++
+		delay := backoff.Backoff(attempt)
+
+		sandwich.dispatchGRPCEvent(dispatchCtx, SandwichEventGRPCReconnecting, GRPCReconnectingEvent{
+			Application: subscription.application,
+			Attempt:     attempt,
+			Delay:       delay,
+		})
+
+		if !sleepContext(ctx, delay) {
+			return nil
+		}
+	}
+}
+
+// receiveGRPC opens a gRPC stream and sends its messages to messages until the stream
+// fails or the context is done. Returns true if any message was received.
+func (sandwich *Sandwich) receiveGRPC(ctx, dispatchCtx context.Context, idleTimeout time.Duration, subscription *grpcSubscription, messages chan<- grpcMessage) (received bool, err error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		idle      atomic.Bool
+		idleTimer *time.Timer
+	)
+
+	if idleTimeout > 0 {
+		idleTimer = time.AfterFunc(idleTimeout, func() {
+			idle.Store(true)
+			cancel()
+		})
+
+		defer idleTimer.Stop()
+	}
+
+	grpcListener, err := sandwich.SandwichClient.Listen(streamCtx, &sandwich_protobuf.ListenRequest{
+		Identifier: subscription.application,
+	})
+	if err != nil {
+		if idle.Load() {
+			err = ErrGRPCIdle
+		}
+
+		return false, fmt.Errorf("failed to listen to grpc: %w", err)
+	}
+
+	sandwich.dispatchGRPCEvent(dispatchCtx, SandwichEventGRPCConnected, GRPCConnectedEvent{
+		Application: subscription.application,
+	})
+
+	defer func() {
+		if ctx.Err() == nil {
+			sandwich.dispatchGRPCEvent(dispatchCtx, SandwichEventGRPCDisconnected, GRPCDisconnectedEvent{
+				Application: subscription.application,
+				Error:       err.Error(),
+			})
+		}
+	}()
+
+	for {
+		var listenResponse sandwich_protobuf.ListenResponse
+
+		err = grpcListener.RecvMsg(&listenResponse)
+		if err != nil {
+			if idle.Load() {
+				err = ErrGRPCIdle
+			}
+
+			return received, fmt.Errorf("failed to receive grpc message: %w", err)
+		}
+
+		received = true
+
+		// Time spent waiting for the event loop does not count towards the idle timeout.
+		if idleTimer != nil {
+			idleTimer.Stop()
+		}
+
+		select {
+		case messages <- grpcMessage{response: &listenResponse, subscription: subscription}:
+		case <-ctx.Done():
+			return received, nil
+		}
+
+		if idleTimer != nil {
+			idleTimer.Reset(idleTimeout)
+		}
+	}
+}
+
+// dispatchGRPCEvent dispatches an event about the state of the gRPC listener to the
+// SandwichEvents.
+func (sandwich *Sandwich) dispatchGRPCEvent(ctx context.Context, eventName string, event any) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		sandwich.Logger.Warn("Failed to marshal grpc event", "event", eventName, "error", err)
+
+		return
+	}
+
+	_ = sandwich.DispatchGRPCPayload(ctx, sandwich_daemon.ProducedPayload{
+		GatewayPayload: discord.GatewayPayload{
+			Op:   discord.GatewayOpDispatch,
+			Type: eventName,
+			Data: data,
+		},
+	})
+}
+
+// isFatalGRPCError returns true if retrying a gRPC call that failed with err will not help.
+func isFatalGRPCError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied, codes.Unimplemented:
+		return true
+	default:
+		return false
+	}
+}
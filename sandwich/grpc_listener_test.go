@@ -0,0 +1,283 @@
+package internal_test
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListenToChannelGRPCEvents(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []sandwich.ListenOption
+		wantBotEvents int64
+	}{
+		{name: "default", opts: nil, wantBotEvents: 0},
+		{name: "applications", opts: []sandwich.ListenOption{sandwich.WithGRPCApplications(sandwichtest.DefaultApplication)}, wantBotEvents: 1},
+		{name: "events", opts: []sandwich.ListenOption{sandwich.WithGRPCEvents(discord.DiscordEventResumed)}, wantBotEvents: 1},
+		{name: "other events", opts: []sandwich.ListenOption{sandwich.WithGRPCEvents(discord.DiscordEventMessageCreate)}, wantBotEvents: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			harness := sandwichtest.New(t)
+
+			var botEvents atomic.Int64
+
+			sandwichEvents := make(chan struct{}, 1)
+
+			sandwich.On(harness.Sandwich.SandwichEvents, sandwich.EventSandwichShardStatusUpdate,
+				func(*sandwich.EventContext, string, int32, sandwich_daemon.ShardStatus) error {
+					sandwichEvents <- struct{}{}
+
+					return nil
+				})
+
+			sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(*sandwich.EventContext) error {
+				botEvents.Add(1)
+
+				return nil
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			listenErr := make(chan error, 1)
+
+			go func() {
+				listenErr <- harness.Sandwich.ListenToChannel(ctx, make(chan []byte), test.opts...)
+			}()
+
+			for len(harness.Client.CallsTo("Listen")) == 0 {
+				time.Sleep(time.Millisecond)
+			}
+
+			// The Sandwich event is sent last, so the Discord event has been dispatched once it is handled.
+			for _, payload := range []sandwich_daemon.ProducedPayload{
+				sandwichtest.Resumed(discord.Resume{}),
+				sandwichtest.SandwichShardStatusUpdate(sandwichtest.DefaultIdentifier, 0, sandwich_daemon.ShardStatusReady),
+			} {
+				err := harness.Client.Send(payload)
+				if err != nil {
+					t.Fatalf("failed to send %s: %v", payload.Type, err)
+				}
+			}
+
+			<-sandwichEvents
+
+			cancel()
+
+			err := <-listenErr
+			if err != nil {
+				t.Fatalf("ListenToChannel returned %v, want nil", err)
+			}
+
+			harness.Close()
+
+			if got := botEvents.Load(); got != test.wantBotEvents {
+				t.Errorf("bot handled %d events, want %d", got, test.wantBotEvents)
+			}
+		})
+	}
+}
+
+// grpcEvent is a SW_GRPC_* event handled by the SandwichEvents.
+type grpcEvent struct {
+	name    string
+	reason  string
+	attempt int
+}
+
+// receiveGRPCEvents returns a channel receiving every SW_GRPC_* event handled by the harness.
+func receiveGRPCEvents(harness *sandwichtest.Harness) <-chan grpcEvent {
+	events := make(chan grpcEvent, 64)
+
+	sandwich.On(harness.Sandwich.SandwichEvents, sandwich.EventSandwichGRPCConnected, func(*sandwich.EventContext, string) error {
+		events <- grpcEvent{name: sandwich.SandwichEventGRPCConnected}
+
+		return nil
+	})
+
+	sandwich.On(harness.Sandwich.SandwichEvents, sandwich.EventSandwichGRPCDisconnected, func(_ *sandwich.EventContext, _ string, reason string) error {
+		events <- grpcEvent{name: sandwich.SandwichEventGRPCDisconnected, reason: reason}
+
+		return nil
+	})
+
+	sandwich.On(harness.Sandwich.SandwichEvents, sandwich.EventSandwichGRPCReconnecting, func(_ *sandwich.EventContext, _ string, attempt int, _ time.Duration) error {
+		events <- grpcEvent{name: sandwich.SandwichEventGRPCReconnecting, attempt: attempt}
+
+		return nil
+	})
+
+	return events
+}
+
+// waitGRPCEvent waits for the next SW_GRPC_* event and checks it is want. The reason of
+// disconnected events must contain the reason of want.
+func waitGRPCEvent(t *testing.T, events <-chan grpcEvent, want grpcEvent) {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		if event.name != want.name || event.attempt != want.attempt || !strings.Contains(event.reason, want.reason) {
+			t.Fatalf("received %+v, want %+v", event, want)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("did not receive %s", want.name)
+	}
+}
+
+// listenGRPC starts ListenToChannel on the harness, which only receives from gRPC.
+func listenGRPC(harness *sandwichtest.Harness, opts ...sandwich.ListenOption) (context.CancelFunc, <-chan error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	listenErr := make(chan error, 1)
+
+	go func() {
+		listenErr <- harness.Sandwich.ListenToChannel(ctx, make(chan []byte), opts...)
+	}()
+
+	return cancel, listenErr
+}
+
+func TestListenGRPCReconnects(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	events := receiveGRPCEvents(harness)
+	statusUpdates := make(chan struct{}, 1)
+
+	sandwich.On(harness.Sandwich.SandwichEvents, sandwich.EventSandwichShardStatusUpdate,
+		func(*sandwich.EventContext, string, int32, sandwich_daemon.ShardStatus) error {
+			statusUpdates <- struct{}{}
+
+			return nil
+		})
+
+	cancel, listenErr := listenGRPC(harness, sandwich.WithReconnectBackoff(time.Millisecond, time.Millisecond*10))
+	defer cancel()
+
+	waitGRPCEvent(t, events, grpcEvent{name: sandwich.SandwichEventGRPCConnected})
+
+	// The backoff grows whilst streams fail without receiving a message, and is reset
+	// once a message is received.
+	for _, test := range []struct {
+		receive     bool
+		wantAttempt int
+	}{
+		{receive: false, wantAttempt: 1},
+		{receive: false, wantAttempt: 2},
+		{receive: true, wantAttempt: 1},
+	} {
+		if test.receive {
+			err := harness.Client.Send(sandwichtest.SandwichShardStatusUpdate(sandwichtest.DefaultIdentifier, 0, sandwich_daemon.ShardStatusReady))
+			if err != nil {
+				t.Fatalf("failed to send: %v", err)
+			}
+
+			<-statusUpdates
+		}
+
+		harness.Client.CloseListeners(errTest)
+
+		waitGRPCEvent(t, events, grpcEvent{name: sandwich.SandwichEventGRPCDisconnected, reason: errTest.Error()})
+		waitGRPCEvent(t, events, grpcEvent{name: sandwich.SandwichEventGRPCReconnecting, attempt: test.wantAttempt})
+		waitGRPCEvent(t, events, grpcEvent{name: sandwich.SandwichEventGRPCConnected})
+	}
+
+	cancel()
+
+	err := <-listenErr
+	if err != nil {
+		t.Fatalf("ListenToChannel returned %v, want nil", err)
+	}
+}
+
+func TestListenGRPCFatalErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      codes.Code
+		stream    bool
+		wantFatal bool
+	}{
+		{name: "unauthenticated", code: codes.Unauthenticated, wantFatal: true},
+		{name: "permission denied", code: codes.PermissionDenied, wantFatal: true},
+		{name: "unimplemented", code: codes.Unimplemented, wantFatal: true},
+		{name: "permission denied on stream", code: codes.PermissionDenied, stream: true, wantFatal: true},
+		{name: "unavailable", code: codes.Unavailable, wantFatal: false},
+		{name: "unavailable on stream", code: codes.Unavailable, stream: true, wantFatal: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			harness := sandwichtest.New(t)
+
+			events := receiveGRPCEvents(harness)
+			grpcErr := status.Error(test.code, "test")
+
+			if !test.stream {
+				harness.Client.SetError("Listen", grpcErr)
+			}
+
+			cancel, listenErr := listenGRPC(harness, sandwich.WithReconnectBackoff(time.Millisecond, time.Millisecond))
+			defer cancel()
+
+			if test.stream {
+				waitGRPCEvent(t, events, grpcEvent{name: sandwich.SandwichEventGRPCConnected})
+
+				harness.Client.CloseListeners(grpcErr)
+				waitGRPCEvent(t, events, grpcEvent{name: sandwich.SandwichEventGRPCDisconnected, reason: "test"})
+			}
+
+			if !test.wantFatal {
+				// The listener reconnects until it is stopped.
+				waitGRPCEvent(t, events, grpcEvent{name: sandwich.SandwichEventGRPCReconnecting, attempt: 1})
+
+				cancel()
+			}
+
+			select {
+			case err := <-listenErr:
+				if test.wantFatal && status.Code(err) != test.code {
+					t.Errorf("ListenToChannel returned %v, want %v", err, test.code)
+				}
+
+				if !test.wantFatal && err != nil {
+					t.Errorf("ListenToChannel returned %v, want nil", err)
+				}
+			case <-time.After(time.Second * 5):
+				t.Fatal("ListenToChannel did not return")
+			}
+		})
+	}
+}
+
+func TestListenGRPCIdle(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	events := receiveGRPCEvents(harness)
+
+	cancel, listenErr := listenGRPC(harness,
+		sandwich.WithIdleTimeout(time.Millisecond*20),
+		sandwich.WithReconnectBackoff(time.Millisecond, time.Millisecond))
+	defer cancel()
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		waitGRPCEvent(t, events, grpcEvent{name: sandwich.SandwichEventGRPCConnected})
+		waitGRPCEvent(t, events, grpcEvent{name: sandwich.SandwichEventGRPCDisconnected, reason: sandwich.ErrGRPCIdle.Error()})
+		waitGRPCEvent(t, events, grpcEvent{name: sandwich.SandwichEventGRPCReconnecting, attempt: attempt})
+	}
+
+	cancel()
+
+	err := <-listenErr
+	if err != nil {
+		t.Fatalf("ListenToChannel returned %v, want nil", err)
+	}
+}
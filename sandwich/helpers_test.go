@@ -0,0 +1,78 @@
+package internal_test
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich_protobuf "github.com/WelcomerTeam/Sandwich-Daemon/proto"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+// testApplication is the identifier and application of payloads created by newPayload.
+const testApplication = "test"
+
+// newTestBot returns a Sandwich with a Bot registered for payloads created by newPayload.
+// The application is already known, so dispatching does not need a gRPC connection.
+func newTestBot() (*sandwich.Sandwich, *sandwich.Bot) {
+	sandwichClient := sandwich.NewSandwich(nil, nil, io.Discard)
+	addTestApplication(sandwichClient, testApplication)
+
+	bot := sandwich.NewBot(slog.New(slog.DiscardHandler))
+	sandwichClient.RegisterBot(testApplication, bot)
+
+	return sandwichClient, bot
+}
+
+// addTestApplication adds an application, so payloads of it can be dispatched without a
+// gRPC connection. It must be called before anything is dispatched.
+func addTestApplication(sandwichClient *sandwich.Sandwich, application string) {
+	sandwichClient.Identifiers[application] = &sandwich_protobuf.SandwichApplication{
+		ApplicationIdentifier: application,
+		BotToken:              "test",
+	}
+}
+
+// newPayload returns a dispatch payload of an event on shard 0.
+func newPayload(eventType string, data any) sandwich_daemon.ProducedPayload {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		panic(err)
+	}
+
+	var payload sandwich_daemon.ProducedPayload
+
+	payload.Op = discord.GatewayOpDispatch
+	payload.Type = eventType
+	payload.Data = encoded
+	payload.Metadata = sandwich_daemon.ProducedMetadata{
+		Identifier:  testApplication,
+		Application: testApplication,
+		Shard:       [3]int32{0, 0, 1},
+	}
+
+	return payload
+}
+
+// dispatch dispatches a payload to the bot of its identifier.
+func dispatch(t *testing.T, sandwichClient *sandwich.Sandwich, payload sandwich_daemon.ProducedPayload) {
+	t.Helper()
+
+	err := sandwichClient.DispatchProducedPayload(t.Context(), payload)
+	if err != nil {
+		t.Fatalf("failed to dispatch: %v", err)
+	}
+}
+
+// marshalPayload returns a payload as it is received from the daemon.
+func marshalPayload(payload sandwich_daemon.ProducedPayload) []byte {
+	message, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+
+	return message
+}
@@ -0,0 +1,91 @@
+// Package mqutil contains helpers shared by the MQ clients.
+package mqutil
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StringArg returns a string arg, or the fallback if it is not set.
+func StringArg(args map[string]any, key string, fallback string) (string, error) {
+	value, ok := args[key]
+	if !ok || value == nil {
+		return fallback, nil
+	}
+
+	stringValue, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string, got %T", key, value)
+	}
+
+	if stringValue == "" {
+		return fallback, nil
+	}
+
+	return stringValue, nil
+}
+
+// IntArg returns an integer arg, or the fallback if it is not set. Args decoded from
+// JSON are float64, so whole floats are accepted.
+func IntArg(args map[string]any, key string, fallback int) (int, error) {
+	value, ok := args[key]
+	if !ok || value == nil {
+		return fallback, nil
+	}
+
+	switch typedValue := value.(type) {
+	case int:
+		return typedValue, nil
+	case int64:
+		return int(typedValue), nil
+	case float64:
+		if typedValue != float64(int(typedValue)) {
+			return 0, fmt.Errorf("%s must be a whole number, got %v", key, typedValue)
+		}
+
+		return int(typedValue), nil
+	default:
+		return 0, fmt.Errorf("%s must be a number, got %T", key, value)
+	}
+}
+
+// DurationArg returns a duration arg, or the fallback if it is not set. Durations can be
+// a time.Duration, a string such as "30s" or a number of seconds.
+func DurationArg(args map[string]any, key string, fallback time.Duration) (time.Duration, error) {
+	value, ok := args[key]
+	if !ok || value == nil {
+		return fallback, nil
+	}
+
+	switch typedValue := value.(type) {
+	case time.Duration:
+		return typedValue, nil
+	case string:
+		duration, err := time.ParseDuration(typedValue)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a duration: %w", key, err)
+		}
+
+		return duration, nil
+	case int:
+		return time.Duration(typedValue) * time.Second, nil
+	case float64:
+		return time.Duration(typedValue * float64(time.Second)), nil
+	default:
+		return 0, fmt.Errorf("%s must be a duration, got %T", key, value)
+	}
+}
+
+// SleepContext waits for the duration, returning false if the context is done first.
+func SleepContext(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,69 @@
+package mqutil_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Sandwich/sandwich/internal/mqutil"
+)
+
+func TestIntArg(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    int
+		wantErr bool
+	}{
+		{name: "unset", value: nil, want: 10},
+		{name: "int", value: 5, want: 5},
+		{name: "whole float", value: float64(5), want: 5},
+		{name: "fractional float", value: 5.5, wantErr: true},
+		{name: "string", value: "5", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := mqutil.IntArg(map[string]any{"Count": test.value}, "Count", 10)
+			if (err != nil) != test.wantErr || got != test.want {
+				t.Errorf("IntArg returned %v, %v, want %v (error: %v)", got, err, test.want, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDurationArg(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "unset", value: nil, want: time.Minute},
+		{name: "duration", value: time.Second, want: time.Second},
+		{name: "string", value: "30s", want: time.Second * 30},
+		{name: "seconds", value: 1.5, want: time.Millisecond * 1500},
+		{name: "invalid string", value: "soon", wantErr: true},
+		{name: "bool", value: true, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := mqutil.DurationArg(map[string]any{"Block": test.value}, "Block", time.Minute)
+			if (err != nil) != test.wantErr || got != test.want {
+				t.Errorf("DurationArg returned %v, %v, want %v (error: %v)", got, err, test.want, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestStringArg(t *testing.T) {
+	got, err := mqutil.StringArg(map[string]any{"Address": ""}, "Address", "localhost")
+	if err != nil || got != "localhost" {
+		t.Errorf("StringArg returned %q, %v, want %q", got, err, "localhost")
+	}
+
+	_, err = mqutil.StringArg(map[string]any{"Address": 1}, "Address", "localhost")
+	if err == nil {
+		t.Error("StringArg accepted a number")
+	}
+}
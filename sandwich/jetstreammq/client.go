@@ -0,0 +1,288 @@
+// Package jetstreammq implements a sandwich.AckableMQClient consuming payloads from a
+// NATS JetStream stream with a durable pull consumer.
+package jetstreammq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/internal/mqutil"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNotConnected      = errors.New("client is not connected")
+	ErrAlreadySubscribed = errors.New("client is already subscribed")
+)
+
+var _ sandwich.AckableMQClient = (*Client)(nil)
+
+// Client consumes a subject of a JetStream stream. Every client with the same durable name
+// shares the same consumer, so messages are spread across them and a client that restarts
+// continues where it left off.
+//
+// Messages are received from either Messages or Chan, not both. Messages read from
+// Messages must be acknowledged, such as by Sandwich.ListenToMessages, whereas messages
+// read from Chan are acknowledged as soon as they are read.
+type Client struct {
+	mu sync.Mutex
+
+	conn      *nats.Conn
+	jetStream jetstream.JetStream
+
+	stream        string
+	durable       string
+	maxAckPending int
+	ackWait       time.Duration
+
+	channel        string
+	consumeContext jetstream.ConsumeContext
+	unsubscribed   chan struct{}
+
+	messages chan *sandwich.MQMessage
+
+	// chanData is created by Chan. Whilst subscribed, messages are forwarded to it.
+	chanData chan []byte
+}
+
+// NewClient creates a Client. Connect must be called before Subscribe.
+func NewClient() *Client {
+	return &Client{
+		mu:       sync.Mutex{},
+		messages: make(chan *sandwich.MQMessage),
+	}
+}
+
+func (c *Client) String() string {
+	return "jetstream"
+}
+
+// Channel returns the subject the client is subscribed to.
+func (c *Client) Channel() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.channel
+}
+
+// Connect connects to NATS, reconnecting forever if the connection is lost.
+// The following args are supported:
+//
+//   - Address: comma separated URLs of the NATS servers. Defaults to nats://127.0.0.1:4222.
+//   - Stream: the stream to consume. Defaults to the stream containing the subscribed subject.
+//   - Durable: the name of the durable consumer. Defaults to clientName.
+//   - MaxAckPending: how many messages can be waiting to be acknowledged. Defaults to the server default.
+//   - AckWait: how long until a message that was not acknowledged is delivered again, as a
+//     duration string or seconds. Defaults to the server default of 30 seconds.
+func (c *Client) Connect(_ context.Context, clientName string, args map[string]any) error {
+	address, err := mqutil.StringArg(args, "Address", nats.DefaultURL)
+	if err != nil {
+		return err
+	}
+
+	stream, err := mqutil.StringArg(args, "Stream", "")
+	if err != nil {
+		return err
+	}
+
+	durable, err := mqutil.StringArg(args, "Durable", clientName)
+	if err != nil {
+		return err
+	}
+
+	maxAckPending, err := mqutil.IntArg(args, "MaxAckPending", 0)
+	if err != nil {
+		return err
+	}
+
+	ackWait, err := mqutil.DurationArg(args, "AckWait", 0)
+	if err != nil {
+		return err
+	}
+
+	conn, err := nats.Connect(address,
+		nats.Name(clientName),
+		nats.MaxReconnects(-1),
+		nats.RetryOnFailedConnect(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	jetStream, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+
+		return fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.conn.Close()
+	}
+
+	c.conn = conn
+	c.jetStream = jetStream
+	c.stream = stream
+	c.durable = durable
+	c.maxAckPending = maxAckPending
+	c.ackWait = ackWait
+
+	return nil
+}
+
+// Subscribe creates or updates the durable consumer of the subject and starts receiving
+// its messages.
+func (c *Client) Subscribe(ctx context.Context, channel string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.jetStream == nil {
+		return ErrNotConnected
+	}
+
+	if c.consumeContext != nil {
+		return ErrAlreadySubscribed
+	}
+
+	stream := c.stream
+	if stream == "" {
+		var err error
+
+		stream, err = c.jetStream.StreamNameBySubject(ctx, channel)
+		if err != nil {
+			return fmt.Errorf("failed to find stream of %s: %w", channel, err)
+		}
+	}
+
+	consumer, err := c.jetStream.CreateOrUpdateConsumer(ctx, stream, jetstream.ConsumerConfig{
+		Durable:       c.durable,
+		FilterSubject: channel,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       c.ackWait,
+		MaxAckPending: c.maxAckPending,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create consumer: %w", err)
+	}
+
+	unsubscribed := make(chan struct{})
+
+	consumeContext, err := consumer.Consume(func(msg jetstream.Msg) {
+		message := &sandwich.MQMessage{
+			Data: msg.Data(),
+			Ack: func(_ context.Context) error {
+				return msg.Ack()
+			},
+			Nack: func(_ context.Context) error {
+				return msg.Nak()
+			},
+		}
+
+		select {
+		case c.messages <- message:
+		case <-unsubscribed:
+			_ = msg.Nak()
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", channel, err)
+	}
+
+	c.channel = channel
+	c.consumeContext = consumeContext
+	c.unsubscribed = unsubscribed
+
+	if c.chanData != nil {
+		go c.forward(c.chanData, unsubscribed)
+	}
+
+	return nil
+}
+
+// Unsubscribe stops receiving messages. The durable consumer is kept, so messages
+// published in the meantime are received once subscribed again.
+func (c *Client) Unsubscribe(_ context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.consumeContext == nil {
+		return
+	}
+
+	close(c.unsubscribed)
+	c.consumeContext.Stop()
+
+	c.consumeContext = nil
+	c.unsubscribed = nil
+	c.channel = ""
+}
+
+// Messages returns the channel messages are delivered to. Every message must be
+// acknowledged or it is delivered again once AckWait has passed.
+func (c *Client) Messages() <-chan *sandwich.MQMessage {
+	return c.messages
+}
+
+// Chan returns a channel of the data of every message. Messages are acknowledged as soon
+// as they are read, so they are lost if they are not handled. Messages are forwarded
+// to it whilst subscribed.
+func (c *Client) Chan() chan []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.chanData == nil {
+		c.chanData = make(chan []byte)
+
+		if c.unsubscribed != nil {
+			go c.forward(c.chanData, c.unsubscribed)
+		}
+	}
+
+	return c.chanData
+}
+
+// forward sends the data of messages to the channel returned by Chan, acknowledging them
+// once they are read, until unsubscribed.
+func (c *Client) forward(chanData chan<- []byte, unsubscribed <-chan struct{}) {
+	for {
+		select {
+		case message := <-c.messages:
+			select {
+			case chanData <- message.Data:
+				_ = message.Ack(context.Background())
+			case <-unsubscribed:
+				_ = message.Nack(context.Background())
+
+				return
+			}
+		case <-unsubscribed:
+			return
+		}
+	}
+}
+
+// Close unsubscribes and drains the connection to NATS.
+func (c *Client) Close() error {
+	c.Unsubscribe(context.Background())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+
+	err := c.conn.Drain()
+	c.conn = nil
+	c.jetStream = nil
+
+	return err
+}
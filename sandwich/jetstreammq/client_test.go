@@ -0,0 +1,241 @@
+package jetstreammq_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/jetstreammq"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// These tests need a NATS server with JetStream enabled, such as one started with
+// nats-server -js, and are skipped unless SANDWICH_TEST_NATS_ADDRESS is set.
+const addressEnv = "SANDWICH_TEST_NATS_ADDRESS"
+
+// newStream creates a stream for the test and returns the subject it stores.
+func newStream(t *testing.T, address string) (jetstream.JetStream, string) {
+	t.Helper()
+
+	conn, err := nats.Connect(address)
+	if err != nil {
+		t.Fatalf("failed to connect to nats: %v", err)
+	}
+
+	t.Cleanup(conn.Close)
+
+	jetStream, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatalf("failed to create jetstream context: %v", err)
+	}
+
+	name := fmt.Sprintf("sandwich_test_%d", time.Now().UnixNano())
+
+	_, err = jetStream.CreateStream(t.Context(), jetstream.StreamConfig{
+		Name:     name,
+		Subjects: []string{name + ".>"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create stream: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = jetStream.DeleteStream(context.Background(), name)
+	})
+
+	return jetStream, name + ".events"
+}
+
+func newClient(t *testing.T, address, subject string) *jetstreammq.Client {
+	t.Helper()
+
+	client := jetstreammq.NewClient()
+
+	err := client.Connect(t.Context(), "sandwich-test", map[string]any{
+		"Address": address,
+		"AckWait": "1s",
+	})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	err = client.Subscribe(t.Context(), subject)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	return client
+}
+
+func receive(t *testing.T, client *jetstreammq.Client) *sandwich.MQMessage {
+	t.Helper()
+
+	select {
+	case message := <-client.Messages():
+		return message
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for message")
+
+		return nil
+	}
+}
+
+func TestClientRedeliversNackedMessages(t *testing.T) {
+	address := os.Getenv(addressEnv)
+	if address == "" {
+		t.Skipf("%s is not set", addressEnv)
+	}
+
+	jetStream, subject := newStream(t, address)
+	client := newClient(t, address, subject)
+
+	_, err := jetStream.Publish(t.Context(), subject, []byte("payload"))
+	if err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+
+	message := receive(t, client)
+	if string(message.Data) != "payload" {
+		t.Fatalf("received %q, want %q", message.Data, "payload")
+	}
+
+	err = message.Nack(t.Context())
+	if err != nil {
+		t.Fatalf("failed to nack: %v", err)
+	}
+
+	message = receive(t, client)
+	if string(message.Data) != "payload" {
+		t.Fatalf("received %q after nack, want %q", message.Data, "payload")
+	}
+
+	err = message.Ack(t.Context())
+	if err != nil {
+		t.Fatalf("failed to ack: %v", err)
+	}
+
+	select {
+	case message := <-client.Messages():
+		t.Fatalf("received %q after ack", message.Data)
+	case <-time.After(time.Second * 2):
+	}
+}
+
+func TestClientChan(t *testing.T) {
+	address := os.Getenv(addressEnv)
+	if address == "" {
+		t.Skipf("%s is not set", addressEnv)
+	}
+
+	jetStream, subject := newStream(t, address)
+	client := newClient(t, address, subject)
+
+	// Messages are forwarded to Chan again once subscribed again.
+	for _, data := range []string{"first", "second"} {
+		_, err := jetStream.Publish(t.Context(), subject, []byte(data))
+		if err != nil {
+			t.Fatalf("failed to publish: %v", err)
+		}
+
+		select {
+		case received := <-client.Chan():
+			if string(received) != data {
+				t.Fatalf("received %q, want %q", received, data)
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out waiting for message")
+		}
+
+		client.Unsubscribe(t.Context())
+
+		err = client.Subscribe(t.Context(), subject)
+		if err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+	}
+}
+
+func TestListenToMessages(t *testing.T) {
+	address := os.Getenv(addressEnv)
+	if address == "" {
+		t.Skipf("%s is not set", addressEnv)
+	}
+
+	jetStream, subject := newStream(t, address)
+	client := newClient(t, address, subject)
+
+	harness := sandwichtest.New(t)
+
+	received := make(chan discord.Message, 1)
+
+	harness.Bot.RegisterOnMessageCreateEvent(func(_ *sandwich.EventContext, message discord.Message) error {
+		received <- message
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go func() {
+		_ = harness.Sandwich.ListenToMessages(ctx, client.Messages())
+	}()
+
+	_, err := jetStream.Publish(t.Context(), subject, sandwichtest.Marshal(sandwichtest.MessageCreate(discord.Message{
+		ID:        1,
+		ChannelID: 2,
+		Content:   "hello",
+	})))
+	if err != nil {
+		t.Fatalf("failed to publish: %v", err)
+	}
+
+	select {
+	case message := <-received:
+		if message.Content != "hello" {
+			t.Fatalf("received %q, want %q", message.Content, "hello")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for MESSAGE_CREATE")
+	}
+
+	stream, err := jetStream.StreamNameBySubject(t.Context(), subject)
+	if err != nil {
+		t.Fatalf("failed to find stream: %v", err)
+	}
+
+	// The message is acknowledged once the listener returns.
+	deadline := time.Now().Add(time.Second * 5)
+
+	for {
+		consumer, err := jetStream.Consumer(t.Context(), stream, "sandwich-test")
+		if err != nil {
+			t.Fatalf("failed to get consumer: %v", err)
+		}
+
+		info, err := consumer.Info(t.Context())
+		if err != nil {
+			t.Fatalf("failed to get consumer info: %v", err)
+		}
+
+		if info.NumAckPending == 0 && info.AckFloor.Consumer == 1 {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("message was not acknowledged, %d pending", info.NumAckPending)
+		}
+
+		time.Sleep(time.Millisecond * 50)
+	}
+}
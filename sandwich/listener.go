@@ -0,0 +1,277 @@
+package internal
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// EventListener is a func registered to an event along with how it should be dispatched.
+type EventListener struct {
+	// Func is the typed func of the event, such as OnMessageCreateFuncType.
+	Func any
+
+	// Serial prevents the listener from running alongside other listeners of the
+	// same event when HandlerConcurrency allows listeners to run concurrently.
+	Serial bool
+
+	// Cog is the name of the cog that registered the listener, if any.
+	Cog string
+
+	// RetryPolicy overrides the retry policy of the event for this listener.
+	RetryPolicy *RetryPolicy
+}
+
+// Name returns the name of the listener's func, for use in logs.
+func (l *EventListener) Name() string {
+	value := reflect.ValueOf(l.Func)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return "<nil>"
+	}
+
+	if function := runtime.FuncForPC(value.Pointer()); function != nil {
+		return function.Name()
+	}
+
+	return "<unknown>"
+}
+
+// SetSerial sets if the listener must not run alongside other listeners of the same event.
+func (l *EventListener) SetSerial(serial bool) *EventListener {
+	l.Serial = serial
+
+	return l
+}
+
+// SetRetryPolicy sets the retry policy of the listener, overriding the retry policy of the event.
+func (l *EventListener) SetRetryPolicy(policy *RetryPolicy) *EventListener {
+	l.RetryPolicy = policy
+
+	return l
+}
+
+// asEventListener returns the EventListener of a registered event. Events appended
+// to EventHandler.Events directly are treated as listeners with default options.
+func asEventListener(event any) *EventListener {
+	if listener, ok := event.(*EventListener); ok {
+		return listener
+	}
+
+	return &EventListener{Func: event}
+}
+
+// dispatchListeners calls every listener of the current event whose func is of type F.
+// Listeners run concurrently if the handlers allow it, except for serial listeners which
+// wait for every listener registered before them to finish and run alone.
+func dispatchListeners[F any](eventCtx *EventContext, call func(eventCtx *EventContext, f F) error) {
+	eventCtx.EventHandler.EventsMu.RLock()
+
+	listeners := make([]*EventListener, 0, len(eventCtx.EventHandler.Events))
+	for _, event := range eventCtx.EventHandler.Events {
+		listeners = append(listeners, asEventListener(event))
+	}
+
+	eventCtx.EventHandler.EventsMu.RUnlock()
+
+	concurrency := eventCtx.Handlers.HandlerConcurrency
+
+	// Synchronous dispatches run every listener on the current goroutine, in order.
+	if concurrency <= 1 || eventCtx.synchronous {
+		for index, listener := range listeners {
+			if f, ok := listener.Func.(F); ok {
+				eventCtx.Handlers.invokeListener(eventCtx, listener, index, func(listenerCtx *EventContext) error {
+					return call(listenerCtx, f)
+				})
+			}
+		}
+
+		return
+	}
+
+	wg := sync.WaitGroup{}
+	slots := make(chan struct{}, concurrency)
+
+	for index, listener := range listeners {
+		f, ok := listener.Func.(F)
+		if !ok {
+			continue
+		}
+
+		if listener.Serial {
+			wg.Wait()
+
+			eventCtx.Handlers.invokeListener(eventCtx, listener, index, func(listenerCtx *EventContext) error {
+				return call(listenerCtx, f)
+			})
+
+			continue
+		}
+
+		slots <- struct{}{}
+
+		wg.Go(func() {
+			defer func() { <-slots }()
+
+			eventCtx.Handlers.invokeListener(eventCtx, listener, index, func(listenerCtx *EventContext) error {
+				return call(listenerCtx, f)
+			})
+		})
+	}
+
+	wg.Wait()
+}
+
+// invokeListener calls a listener and passes any error it returns, or any panic, to the
+// ERROR handlers as a *HandlerError, so they do not stop the other listeners of the event.
+// Failed listeners are retried in the background if they have a retry policy. Retries
+// that are abandoned when the handlers are closed are sent to the dead-letter sink.
+func (h *Handlers) invokeListener(eventCtx *EventContext, listener *EventListener, index int, call func(listenerCtx *EventContext) error) {
+	h.invokeListenerAttempt(eventCtx, listener, index, call, 1)
+}
+
+func (h *Handlers) invokeListenerAttempt(eventCtx *EventContext, listener *EventListener, index int, call func(listenerCtx *EventContext) error, attempt int) {
+	err := h.callListener(eventCtx, listener, call)
+	if err == nil {
+		return
+	}
+
+	policy := h.listenerRetryPolicy(eventCtx.EventHandler.eventName, listener)
+
+	reason := DeadLetterHandlerFailed
+	if attempt > 1 {
+		reason = DeadLetterRetriesExhausted
+	}
+
+	if policy.ShouldRetry(err, attempt) {
+		backoff := policy.Backoff(attempt)
+
+		var retried bool
+
+		// Synchronous dispatches retry on the current goroutine so their errors can be collected.
+		if eventCtx.synchronous {
+			retried = sleepContext(eventCtx.Context, backoff)
+		} else {
+			// Retries outlive the dispatch, so they are not cancelled along with it.
+			retryCtx := *eventCtx
+			if retryCtx.Context != nil {
+				retryCtx.Context = context.WithoutCancel(retryCtx.Context)
+			}
+
+			// Acknowledged dispatches are settled once every retry has finished.
+			retryCtx.retryPending()
+
+			retried = h.scheduleRetry(backoff, func() {
+				h.invokeListenerAttempt(&retryCtx, listener, index, call, attempt+1)
+				retryCtx.settleDispatch()
+			}, func() {
+				h.listenerFailed(&retryCtx, listener, index, err, attempt, DeadLetterRetryAbandoned)
+				retryCtx.settleDispatch()
+			})
+
+			if !retried {
+				retryCtx.settleDispatch()
+			}
+		}
+
+		if retried {
+			eventCtx.Logger.Debug("Retrying event handler",
+				"handler", listener.Name(),
+				"event", eventCtx.EventHandler.eventName,
+				"attempt", attempt,
+				"backoff", backoff,
+				"error", err)
+
+			if eventCtx.synchronous {
+				h.invokeListenerAttempt(eventCtx, listener, index, call, attempt+1)
+			}
+
+			return
+		}
+
+		reason = DeadLetterRetryAbandoned
+	}
+
+	h.listenerFailed(eventCtx, listener, index, err, attempt, reason)
+}
+
+// listenerFailed passes the error of a listener that will not be retried to the ERROR
+// handlers and the dead-letter sink.
+func (h *Handlers) listenerFailed(eventCtx *EventContext, listener *EventListener, index int, err error, attempt int, reason DeadLetterReason) {
+	errorCtx := *eventCtx
+
+	handlerErr := newHandlerError(&errorCtx, listener, index, err)
+	handlerErr.Attempts = attempt
+
+	eventCtx.collectError(handlerErr)
+
+	h.WrapFuncType(&errorCtx, handlerErr)
+
+	deadLetter := newDeadLetter(eventCtx, reason, err)
+	deadLetter.Handler = listener.Name()
+	deadLetter.Attempts = attempt
+
+	eventCtx.Sandwich.deadLetter(eventCtx.Context, deadLetter)
+}
+
+// callListener calls a listener with its own copy of the event context, which has the
+// deadline of the event applied, and reports the listener if it runs slowly. Panics are
+// recovered and returned as a *PanicError.
+func (h *Handlers) callListener(eventCtx *EventContext, listener *EventListener, call func(listenerCtx *EventContext) error) (err error) {
+	eventName := eventCtx.EventHandler.eventName
+
+	listenerCtx := *eventCtx
+
+	if listenerCtx.Context == nil {
+		listenerCtx.Context = context.Background()
+	}
+
+	defer func() {
+		errorValue := recover()
+		if errorValue != nil {
+			panicErr := newPanicError(errorValue, listener.Name())
+			listenerCtx.Sandwich.handleEventPanic(&listenerCtx, listenerCtx.Payload, panicErr)
+
+			err = panicErr
+		}
+	}()
+
+	if timeout := h.GetEventTimeout(eventName); timeout > 0 {
+		ctx, cancel := context.WithTimeout(listenerCtx.Context, timeout)
+		defer cancel()
+
+		listenerCtx.Context = ctx
+	}
+
+	var slowTimer *time.Timer
+
+	start := time.Now()
+
+	if threshold := h.SlowHandlerThreshold; threshold > 0 {
+		slowTimer = time.AfterFunc(threshold, func() {
+			h.reportSlowListener(eventCtx, listener, "Event handler is running slowly", time.Since(start))
+		})
+	}
+
+	err = call(&listenerCtx)
+
+	if slowTimer != nil && !slowTimer.Stop() {
+		h.reportSlowListener(eventCtx, listener, "Slow event handler finished", time.Since(start))
+	}
+
+	return err
+}
+
+func (h *Handlers) reportSlowListener(eventCtx *EventContext, listener *EventListener, message string, elapsed time.Duration) {
+	var guildID int64
+	if eventCtx.Guild != nil {
+		guildID = int64(eventCtx.Guild.ID)
+	}
+
+	eventCtx.Logger.Warn(message,
+		"handler", listener.Name(),
+		"event", eventCtx.EventHandler.eventName,
+		"guild", guildID,
+		"elapsed", elapsed)
+}
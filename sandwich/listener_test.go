@@ -0,0 +1,258 @@
+package internal_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+// listenerRun is when a listener started or finished.
+type listenerRun struct {
+	index   int
+	started bool
+}
+
+func TestListenerConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		serial      []bool
+		wantRunning int
+	}{
+		{name: "one after another by default", concurrency: 0, serial: []bool{false, false, false}, wantRunning: 1},
+		{name: "concurrent", concurrency: 3, serial: []bool{false, false, false}, wantRunning: 3},
+		{name: "limited", concurrency: 2, serial: []bool{false, false, false, false}, wantRunning: 2},
+		{name: "serial listener", concurrency: 4, serial: []bool{false, false, true, false}, wantRunning: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sandwichClient, bot := newTestBot()
+			bot.Handlers.SetHandlerConcurrency(test.concurrency)
+
+			var (
+				mu   sync.Mutex
+				runs []listenerRun
+			)
+
+			for index, serial := range test.serial {
+				bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+					mu.Lock()
+					runs = append(runs, listenerRun{index: index, started: true})
+					mu.Unlock()
+
+					time.Sleep(time.Millisecond * 20)
+
+					mu.Lock()
+					runs = append(runs, listenerRun{index: index, started: false})
+					mu.Unlock()
+
+					return nil
+				}).SetSerial(serial)
+			}
+
+			dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+			deadline := time.Now().Add(time.Second * 5)
+
+			for {
+				mu.Lock()
+				done := len(runs) == len(test.serial)*2
+				mu.Unlock()
+
+				if done {
+					break
+				}
+
+				if time.Now().After(deadline) {
+					t.Fatalf("listeners did not finish")
+				}
+
+				time.Sleep(time.Millisecond)
+			}
+
+			running, maxRunning := 0, 0
+			finished := make([]bool, len(test.serial))
+
+			for i, run := range runs {
+				if !run.started {
+					running--
+					finished[run.index] = true
+
+					continue
+				}
+
+				running++
+				maxRunning = max(maxRunning, running)
+
+				if test.concurrency <= 1 && run.index != i/2 {
+					t.Errorf("listener %d started out of order", run.index)
+				}
+
+				// Serial listeners wait for every listener before them and run alone.
+				if test.serial[run.index] {
+					if slices.Contains(finished[:run.index], false) {
+						t.Errorf("serial listener %d started before the listeners registered before it finished", run.index)
+					}
+
+					if next := runs[i+1]; next.index != run.index || next.started {
+						t.Errorf("listener %d ran alongside serial listener %d", next.index, run.index)
+					}
+				}
+			}
+
+			if maxRunning != test.wantRunning {
+				t.Errorf("%d listeners ran at the same time, want %d", maxRunning, test.wantRunning)
+			}
+		})
+	}
+}
+
+func TestListenerTimeout(t *testing.T) {
+	tests := []struct {
+		name           string
+		handlerTimeout time.Duration
+		eventTimeout   *time.Duration
+		wantTimeout    time.Duration
+	}{
+		{name: "no timeout", wantTimeout: 0},
+		{name: "handler timeout", handlerTimeout: time.Minute, wantTimeout: time.Minute},
+		{name: "event timeout", handlerTimeout: time.Minute, eventTimeout: new(time.Hour), wantTimeout: time.Hour},
+		{name: "event without timeout", handlerTimeout: time.Minute, eventTimeout: new(time.Duration(0)), wantTimeout: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sandwichClient, bot := newTestBot()
+			bot.Handlers.SetHandlerTimeout(test.handlerTimeout)
+
+			if test.eventTimeout != nil {
+				bot.Handlers.SetEventTimeout(discord.DiscordEventResumed, *test.eventTimeout)
+			}
+
+			type listenerDeadline struct {
+				deadline    time.Time
+				hasDeadline bool
+			}
+
+			deadlines := make(chan listenerDeadline, 1)
+
+			bot.Handlers.RegisterOnResumedEvent(func(eventCtx *sandwich.EventContext) error {
+				deadline, hasDeadline := eventCtx.Context.Deadline()
+				deadlines <- listenerDeadline{deadline: deadline, hasDeadline: hasDeadline}
+
+				return nil
+			})
+
+			start := time.Now()
+
+			dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+			var got listenerDeadline
+
+			select {
+			case got = <-deadlines:
+			case <-time.After(time.Second * 5):
+				t.Fatal("event was not handled")
+			}
+
+			if got.hasDeadline != (test.wantTimeout > 0) {
+				t.Fatalf("listener has a deadline: %v, want %v", got.hasDeadline, test.wantTimeout > 0)
+			}
+
+			if got.hasDeadline && (got.deadline.Before(start.Add(test.wantTimeout)) || got.deadline.After(time.Now().Add(test.wantTimeout))) {
+				t.Errorf("listener has deadline in %v, want %v", got.deadline.Sub(start), test.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestListenerTimeoutCancels(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+	bot.Handlers.SetHandlerTimeout(time.Millisecond * 10)
+
+	cancelled := make(chan error, 1)
+
+	bot.Handlers.RegisterOnResumedEvent(func(eventCtx *sandwich.EventContext) error {
+		<-eventCtx.Context.Done()
+		cancelled <- eventCtx.Context.Err()
+
+		return eventCtx.Context.Err()
+	})
+
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+	select {
+	case err := <-cancelled:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("listener context returned %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("listener was not cancelled")
+	}
+}
+
+// syncBuffer is a bytes.Buffer that can be written to from multiple goroutines.
+type syncBuffer struct {
+	mu     sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buffer.String()
+}
+
+func TestSlowListener(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+	bot.Handlers.SetSlowHandlerThreshold(time.Millisecond * 10)
+
+	logs := &syncBuffer{}
+	sandwichClient.Logger = slog.New(slog.NewTextHandler(logs, nil))
+
+	finished := make(chan struct{})
+
+	bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+		time.Sleep(time.Millisecond * 50)
+
+		return nil
+	})
+
+	bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+		close(finished)
+
+		return nil
+	})
+
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second * 5):
+		t.Fatal("event was not handled")
+	}
+
+	for _, message := range []string{"Event handler is running slowly", "Slow event handler finished"} {
+		if !strings.Contains(logs.String(), message) {
+			t.Errorf("slow listener was not reported with %q", message)
+		}
+	}
+}
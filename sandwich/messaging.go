@@ -13,3 +13,25 @@ type MQClient interface {
 	Unsubscribe(ctx context.Context)
 	Chan() chan []byte
 }
+
+// MQMessage is a message delivered by an AckableMQClient. Exactly one of Ack and Nack
+// must be called once the message has been handled.
+type MQMessage struct {
+	Data []byte
+
+	// Ack tells the queue the message was handled, so it is not delivered again.
+	Ack func(ctx context.Context) error
+
+	// Nack tells the queue the message was not handled, so it is delivered again.
+	Nack func(ctx context.Context) error
+}
+
+// AckableMQClient is an MQClient whose messages are only removed from the queue once
+// they are acknowledged, so messages that were not handled are delivered again.
+type AckableMQClient interface {
+	MQClient
+
+	// Messages returns the channel messages are delivered to. Messages delivered
+	// here are not sent to Chan.
+	Messages() <-chan *MQMessage
+}
@@ -0,0 +1,34 @@
+package internal
+
+import (
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+)
+
+// DispatchFunc dispatches a payload to the listeners of an event.
+type DispatchFunc func(eventCtx *EventContext, eventName string, payload sandwich_daemon.ProducedPayload) error
+
+// Middleware wraps the dispatch of a payload to the listeners of an event. A middleware can
+// run code before and after the event is handled, or stop the event from being handled by
+// returning without calling next. Custom events, such as GUILD_JOIN, are dispatched from the
+// parser of another event so pass through the middleware a second time with their own name.
+type Middleware func(next DispatchFunc) DispatchFunc
+
+// Use adds middleware around the dispatch of every event. Middleware runs in the order it is
+// added, so the first middleware added is the outermost.
+func (h *Handlers) Use(middlewares ...Middleware) {
+	h.middlewaresMu.Lock()
+	h.Middlewares = append(h.Middlewares, middlewares...)
+	h.middlewaresMu.Unlock()
+}
+
+// withMiddleware wraps a DispatchFunc with every middleware.
+func (h *Handlers) withMiddleware(dispatch DispatchFunc) DispatchFunc {
+	h.middlewaresMu.RLock()
+	defer h.middlewaresMu.RUnlock()
+
+	for i := len(h.Middlewares) - 1; i >= 0; i-- {
+		dispatch = h.Middlewares[i](dispatch)
+	}
+
+	return dispatch
+}
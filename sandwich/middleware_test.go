@@ -0,0 +1,139 @@
+package internal_test
+
+import (
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+// recordMiddleware returns a middleware that records its name and the event it was called
+// with, before and after calling next.
+func recordMiddleware(name string, calls *[]string) sandwich.Middleware {
+	return func(next sandwich.DispatchFunc) sandwich.DispatchFunc {
+		return func(eventCtx *sandwich.EventContext, eventName string, payload sandwich_daemon.ProducedPayload) error {
+			*calls = append(*calls, name+" "+eventName)
+
+			err := next(eventCtx, eventName, payload)
+
+			*calls = append(*calls, name+" done")
+
+			return err
+		}
+	}
+}
+
+// finishMiddleware returns a middleware that closes finished once the event it was first
+// called with has been handled.
+func finishMiddleware(finished chan struct{}) sandwich.Middleware {
+	var called atomic.Bool
+
+	return func(next sandwich.DispatchFunc) sandwich.DispatchFunc {
+		return func(eventCtx *sandwich.EventContext, eventName string, payload sandwich_daemon.ProducedPayload) error {
+			if called.Swap(true) {
+				return next(eventCtx, eventName, payload)
+			}
+
+			defer close(finished)
+
+			return next(eventCtx, eventName, payload)
+		}
+	}
+}
+
+// waitFinished waits for finished to be closed.
+func waitFinished(t *testing.T, finished chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second * 5):
+		t.Fatal("event was not handled")
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	var calls []string
+
+	finished := make(chan struct{})
+
+	bot.Handlers.Use(finishMiddleware(finished), recordMiddleware("first", &calls), recordMiddleware("second", &calls))
+
+	bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+		calls = append(calls, "listener")
+
+		return nil
+	})
+
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+	waitFinished(t, finished)
+
+	want := []string{"first RESUMED", "second RESUMED", "listener", "second done", "first done"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("called %v, want %v", calls, want)
+	}
+}
+
+func TestMiddlewareCustomEvents(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	var calls []string
+
+	finished := make(chan struct{})
+
+	bot.Handlers.Use(finishMiddleware(finished), recordMiddleware("middleware", &calls))
+
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventGuildCreate, discord.Guild{ID: 1}))
+	waitFinished(t, finished)
+
+	want := []string{"middleware GUILD_CREATE", "middleware GUILD_JOIN", "middleware done", "middleware done"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("called %v, want %v", calls, want)
+	}
+}
+
+func TestMiddlewareStopsDispatch(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "skipped", err: nil},
+		{name: "failed", err: errors.New("middleware failed")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sandwichClient, bot := newTestBot()
+
+			finished := make(chan struct{})
+
+			bot.Handlers.Use(finishMiddleware(finished), func(sandwich.DispatchFunc) sandwich.DispatchFunc {
+				return func(*sandwich.EventContext, string, sandwich_daemon.ProducedPayload) error {
+					return test.err
+				}
+			})
+
+			var called atomic.Bool
+
+			bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+				called.Store(true)
+
+				return nil
+			})
+
+			dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+			waitFinished(t, finished)
+
+			if called.Load() {
+				t.Error("listener was called without the middleware calling next")
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+)
+
+// WorkerKey identifies a worker queue. Payloads with the same key are handled
+// in the order they were dispatched by a single worker.
+type WorkerKey struct {
+	Application string
+	Partition   int32
+
+	// PriorityLane is set for queues that only handle high priority events,
+	// separate from the queue of the partition.
+	PriorityLane bool
+}
+
+// PartitionFunc returns the key of the worker queue a payload is dispatched to.
+type PartitionFunc func(payload *sandwich_daemon.ProducedPayload) WorkerKey
+
+// PartitionByShard is the default PartitionFunc. It gives every shard of every
+// application its own worker queue.
+func PartitionByShard(payload *sandwich_daemon.ProducedPayload) WorkerKey {
+	return WorkerKey{
+		Application: payload.Metadata.Application,
+		Partition:   payload.Metadata.Shard[1],
+	}
+}
+
+// PartitionByGuild returns a PartitionFunc that spreads the payloads of each application
+// over a fixed number of worker queues by guild ID. Events for the same guild are still
+// handled in order, but a slow guild only delays guilds that share its queue.
+// Events without a guild, such as direct messages, are partitioned by channel or user ID
+// and anything else is partitioned by shard.
+func PartitionByGuild(workers int32) PartitionFunc {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	return func(payload *sandwich_daemon.ProducedPayload) WorkerKey {
+		partitionID, ok, err := payloadPartitionID(payload)
+
+		// Malformed payloads are reported by their parser, so they are only kept in order with their shard.
+		if err != nil || !ok {
+			partitionID = discord.Snowflake(payload.Metadata.Shard[1])
+		}
+
+		return WorkerKey{
+			Application: payload.Metadata.Application,
+			Partition:   int32(hashSnowflake(partitionID) % uint64(workers)),
+		}
+	}
+}
+
+type partitionPayload struct {
+	ID        *discord.Snowflake `json:"id"`
+	GuildID   *discord.Snowflake `json:"guild_id"`
+	ChannelID *discord.Snowflake `json:"channel_id"`
+	UserID    *discord.Snowflake `json:"user_id"`
+	User      *struct {
+		ID *discord.Snowflake `json:"id"`
+	} `json:"user"`
+}
+
+// payloadPartitionID returns the ID of the guild a payload belongs to, falling
+// back to the channel or user for events sent outside of guilds.
+func payloadPartitionID(payload *sandwich_daemon.ProducedPayload) (discord.Snowflake, bool, error) {
+	partition, err := decodePartitionPayload(payload.Type, payload.Data)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if guildID, ok := partition.guildID(payload.Type); ok {
+		return guildID, true, nil
+	}
+
+	switch {
+	case partition.ChannelID != nil && !partition.ChannelID.IsNil():
+		return *partition.ChannelID, true, nil
+	case partition.UserID != nil && !partition.UserID.IsNil():
+		return *partition.UserID, true, nil
+	case partition.User != nil && partition.User.ID != nil:
+		return *partition.User.ID, true, nil
+	default:
+		return 0, false, nil
+	}
+}
+
+// payloadGuildID returns the ID of the guild a payload belongs to.
+func payloadGuildID(payload *sandwich_daemon.ProducedPayload) (discord.Snowflake, bool, error) {
+	partition, err := decodePartitionPayload(payload.Type, payload.Data)
+	if err != nil {
+		return 0, false, err
+	}
+
+	guildID, ok := partition.guildID(payload.Type)
+
+	return guildID, ok, nil
+}
+
+// guildID returns the ID of the guild the data of an event belongs to.
+func (partition *partitionPayload) guildID(eventName string) (discord.Snowflake, bool) {
+	switch {
+	case isGuildObjectEvent(eventName) && partition.ID != nil:
+		return *partition.ID, true
+	case partition.GuildID != nil && !partition.GuildID.IsNil():
+		return *partition.GuildID, true
+	default:
+		return 0, false
+	}
+}
+
+// decodePartitionPayload decodes the IDs of the data of an event. Only the top level of
+// the data is read and every other field is skipped without being decoded. Decoding stops
+// as soon as the guild ID is found, as it is used before any other ID.
+func decodePartitionPayload(eventName string, data []byte) (partitionPayload, error) {
+	var partition partitionPayload
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return partition, fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	if token != json.Delim('{') {
+		return partition, fmt.Errorf("failed to decode payload: data is %v, want an object", token)
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return partition, fmt.Errorf("failed to decode payload: %w", err)
+		}
+
+		var value any
+
+		switch token {
+		case "id":
+			value = &partition.ID
+		case "guild_id":
+			value = &partition.GuildID
+		case "channel_id":
+			value = &partition.ChannelID
+		case "user_id":
+			value = &partition.UserID
+		case "user":
+			value = &partition.User
+		default:
+			value = &json.RawMessage{}
+		}
+
+		err = decoder.Decode(value)
+		if err != nil {
+			return partition, fmt.Errorf("failed to decode payload field %v: %w", token, err)
+		}
+
+		if _, ok := partition.guildID(eventName); ok {
+			return partition, nil
+		}
+	}
+
+	return partition, nil
+}
+
+// isGuildObjectEvent returns true if the payload data of an event is a guild,
+// so its ID is the guild ID.
+func isGuildObjectEvent(eventName string) bool {
+	switch eventName {
+	case discord.DiscordEventGuildCreate,
+		discord.DiscordEventGuildUpdate,
+		discord.DiscordEventGuildDelete,
+		discord.DiscordEventGuildJoin,
+		discord.DiscordEventGuildAvailable,
+		discord.DiscordEventGuildLeave,
+		discord.DiscordEventGuildUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// hashSnowflake mixes the bits of a snowflake so sequential IDs are spread evenly.
+func hashSnowflake(id discord.Snowflake) uint64 {
+	x := uint64(id)
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+
+	return x
+}
@@ -0,0 +1,186 @@
+package internal_test
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+func TestPartitionByShard(t *testing.T) {
+	payload := newPayload(discord.DiscordEventResumed, discord.Resume{})
+	payload.Metadata.Application = "a"
+	payload.Metadata.Shard = [3]int32{0, 3, 4}
+
+	want := sandwich.WorkerKey{Application: "a", Partition: 3}
+	if key := sandwich.PartitionByShard(&payload); key != want {
+		t.Errorf("PartitionByShard = %+v, want %+v", key, want)
+	}
+}
+
+// TestWorkerPoolsByApplication makes sure applications sharing a shard number are
+// handled by different workers.
+func TestWorkerPoolsByApplication(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+		<-release
+
+		return nil
+	})
+
+	addTestApplication(sandwichClient, "a")
+	addTestApplication(sandwichClient, "b")
+
+	for _, application := range []string{"a", "b"} {
+		payload := newPayload(discord.DiscordEventResumed, discord.Resume{})
+		payload.Metadata.Application = application
+
+		dispatch(t, sandwichClient, payload)
+	}
+
+	bot.Handlers.WorkerPoolMu.RLock()
+	keys := slices.Collect(maps.Keys(bot.Handlers.WorkerPool))
+	bot.Handlers.WorkerPoolMu.RUnlock()
+
+	slices.SortFunc(keys, func(a, b sandwich.WorkerKey) int {
+		return strings.Compare(a.Application, b.Application)
+	})
+
+	want := []sandwich.WorkerKey{{Application: "a"}, {Application: "b"}}
+	if !slices.Equal(keys, want) {
+		t.Errorf("worker pools have keys %+v, want %+v", keys, want)
+	}
+}
+
+func TestPartitionByGuild(t *testing.T) {
+	partitionFunc := sandwich.PartitionByGuild(1024)
+
+	payload := func(eventType, data string) sandwich_daemon.ProducedPayload {
+		return newPayload(eventType, json.RawMessage(data))
+	}
+
+	malformed := func(eventType, data string) sandwich_daemon.ProducedPayload {
+		payload := newPayload(eventType, nil)
+		payload.Data = []byte(data)
+
+		return payload
+	}
+
+	onShard := func(payload sandwich_daemon.ProducedPayload, shardID int32) sandwich_daemon.ProducedPayload {
+		payload.Metadata.Shard = [3]int32{0, shardID, 2}
+
+		return payload
+	}
+
+	ofApplication := func(payload sandwich_daemon.ProducedPayload, application string) sandwich_daemon.ProducedPayload {
+		payload.Metadata.Application = application
+
+		return payload
+	}
+
+	tests := []struct {
+		name string
+		a, b sandwich_daemon.ProducedPayload
+		same bool
+	}{
+		{
+			name: "same guild",
+			a:    payload(discord.DiscordEventMessageCreate, `{"guild_id":"10","channel_id":"20"}`),
+			b:    payload(discord.DiscordEventGuildMemberAdd, `{"guild_id":"10","user":{"id":"30"}}`),
+			same: true,
+		},
+		{
+			name: "guild object",
+			a:    payload(discord.DiscordEventGuildUpdate, `{"id":"10"}`),
+			b:    payload(discord.DiscordEventMessageCreate, `{"guild_id":"10","channel_id":"20"}`),
+			same: true,
+		},
+		{
+			name: "different guilds",
+			a:    payload(discord.DiscordEventMessageCreate, `{"guild_id":"10","channel_id":"20"}`),
+			b:    payload(discord.DiscordEventMessageCreate, `{"guild_id":"11","channel_id":"20"}`),
+			same: false,
+		},
+		{
+			name: "direct messages by channel",
+			a:    payload(discord.DiscordEventMessageCreate, `{"channel_id":"20"}`),
+			b:    payload(discord.DiscordEventTypingStart, `{"channel_id":"20","user_id":"30"}`),
+			same: true,
+		},
+		{
+			name: "without ids by shard",
+			a:    onShard(payload(discord.DiscordEventResumed, `{}`), 1),
+			b:    onShard(payload(discord.DiscordEventResumed, `{}`), 1),
+			same: true,
+		},
+		{
+			name: "different shards",
+			a:    onShard(payload(discord.DiscordEventResumed, `{}`), 0),
+			b:    onShard(payload(discord.DiscordEventResumed, `{}`), 1),
+			same: false,
+		},
+		{
+			name: "nested ids",
+			a:    payload(discord.DiscordEventMessageCreate, `{"referenced_message":{"guild_id":"11","channel_id":"21"},"guild_id":"10"}`),
+			b:    payload(discord.DiscordEventMessageCreate, `{"guild_id":"10"}`),
+			same: true,
+		},
+		{
+			name: "malformed by shard",
+			a:    onShard(malformed(discord.DiscordEventMessageCreate, `{"channel_id":"20",`), 1),
+			b:    onShard(payload(discord.DiscordEventResumed, `{}`), 1),
+			same: true,
+		},
+		{
+			name: "malformed on different shards",
+			a:    onShard(malformed(discord.DiscordEventMessageCreate, `{"channel_id":"20",`), 0),
+			b:    onShard(malformed(discord.DiscordEventMessageCreate, `{"channel_id":"20",`), 1),
+			same: false,
+		},
+		{
+			name: "not an object by shard",
+			a:    onShard(payload(discord.DiscordEventMessageCreate, `["20"]`), 1),
+			b:    onShard(payload(discord.DiscordEventResumed, `{}`), 1),
+			same: true,
+		},
+		{
+			name: "different applications",
+			a:    ofApplication(payload(discord.DiscordEventMessageCreate, `{"guild_id":"10"}`), "a"),
+			b:    ofApplication(payload(discord.DiscordEventMessageCreate, `{"guild_id":"10"}`), "b"),
+			same: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := partitionFunc(&test.a), partitionFunc(&test.b)
+			if (a == b) != test.same {
+				t.Errorf("payloads have keys %+v and %+v, want same keys to be %v", a, b, test.same)
+			}
+		})
+	}
+}
+
+func TestPartitionByGuildWorkers(t *testing.T) {
+	for _, workers := range []int32{-1, 0, 1, 4} {
+		partitionFunc := sandwich.PartitionByGuild(workers)
+
+		for guildID := range discord.Snowflake(64) {
+			payload := newPayload(discord.DiscordEventMessageCreate, discord.Message{GuildID: &guildID})
+
+			key := partitionFunc(&payload)
+			if key.Partition < 0 || key.Partition >= max(workers, 1) {
+				t.Fatalf("PartitionByGuild(%d) returned partition %d", workers, key.Partition)
+			}
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+)
+
+// Recorder receives every raw message read by ListenToChannel before it is dispatched.
+// Record may be called from multiple goroutines and must not keep the message after returning.
+type Recorder interface {
+	Record(message []byte) error
+}
+
+// WithRecorder records every message received by ListenToChannel, from both the channel
+// and gRPC, before it is dispatched.
+func WithRecorder(recorder Recorder) ListenOption {
+	return func(options *listenOptions) {
+		options.recorder = recorder
+	}
+}
+
+// FileRecorderOptions controls where a FileRecorder writes and which messages it records.
+type FileRecorderOptions struct {
+	// Directory is where recordings are written.
+	Directory string
+
+	// Prefix is the start of the name of every recording. Defaults to "sandwich".
+	Prefix string
+
+	// MaxSize is the number of uncompressed bytes written to a recording before a new one
+	// is started. A value of 0 or less does not rotate recordings by size.
+	MaxSize int64
+
+	// MaxAge is how long a recording is written to before a new one is started.
+	// A value of 0 or less does not rotate recordings by age.
+	MaxAge time.Duration
+
+	// SampleRate is the fraction of events recorded, between 0 and 1.
+	// A value of 0 or less records every event.
+	SampleRate float64
+
+	// EventSampleRates overrides the sample rate of specific event types.
+	// A rate of 0 stops an event type from being recorded.
+	EventSampleRates map[string]float64
+
+	// GuildSampleRate is the fraction of guilds whose events are recorded, between 0 and 1.
+	// Guilds are picked by their ID, so every event of a sampled guild is recorded.
+	// Events that do not belong to a guild are not affected. A value of 0 or less records every guild.
+	GuildSampleRate float64
+
+	// FlushInterval is how often recorded messages are written to the current recording.
+	// Defaults to 1 second. A negative value disables it, so messages are only written
+	// once enough are buffered or Flush is called.
+	FlushInterval time.Duration
+}
+
+const defaultRecorderFlushInterval = time.Second
+
+// FileRecorder writes messages to gzip compressed JSON lines files, which can be replayed
+// with Replay. Recordings are named after their prefix and when they were started.
+type FileRecorder struct {
+	mu sync.Mutex
+
+	options FileRecorderOptions
+
+	file *os.File
+	gzip *gzip.Writer
+	size int64
+
+	// rotateTimer finishes the current recording once it reaches MaxAge.
+	rotateTimer *time.Timer
+
+	// err is an error from finishing a recording in the background, returned by the next Record.
+	err error
+
+	sampled atomic.Uint64
+	closed  atomic.Bool
+	done    chan struct{}
+}
+
+// NewFileRecorder creates a FileRecorder, creating its directory if it does not exist.
+// Close must be called to finish the current recording and stop flushing it.
+func NewFileRecorder(options FileRecorderOptions) (*FileRecorder, error) {
+	if options.Prefix == "" {
+		options.Prefix = "sandwich"
+	}
+
+	if options.FlushInterval == 0 {
+		options.FlushInterval = defaultRecorderFlushInterval
+	}
+
+	err := os.MkdirAll(options.Directory, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+
+	recorder := &FileRecorder{
+		mu:      sync.Mutex{},
+		options: options,
+		done:    make(chan struct{}),
+	}
+
+	if options.FlushInterval > 0 {
+		go recorder.flushEvery(options.FlushInterval)
+	}
+
+	return recorder, nil
+}
+
+func (recorder *FileRecorder) Record(message []byte) error {
+	message = bytes.TrimSpace(message)
+	if len(message) == 0 {
+		return nil
+	}
+
+	// Each message must be a single line.
+	if bytes.ContainsAny(message, "\r\n") {
+		var compacted bytes.Buffer
+
+		err := json.Compact(&compacted, message)
+		if err != nil {
+			return fmt.Errorf("failed to compact message: %w", err)
+		}
+
+		message = compacted.Bytes()
+	}
+
+	if recorder.closed.Load() {
+		return os.ErrClosed
+	}
+
+	if !recorder.shouldRecord(message) {
+		return nil
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if recorder.closed.Load() {
+		return os.ErrClosed
+	}
+
+	if err := recorder.err; err != nil {
+		recorder.err = nil
+
+		return err
+	}
+
+	if recorder.gzip != nil && recorder.shouldRotate() {
+		err := recorder.closeFile()
+		if err != nil {
+			return err
+		}
+	}
+
+	if recorder.gzip == nil {
+		err := recorder.openFile()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, data := range [][]byte{message, {'\n'}} {
+		written, err := recorder.gzip.Write(data)
+		recorder.size += int64(written)
+
+		if err != nil {
+			return fmt.Errorf("failed to write recording: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Flush writes any buffered messages to the current recording. Recordings are also
+// flushed every FlushInterval.
+func (recorder *FileRecorder) Flush() error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if recorder.gzip == nil {
+		return nil
+	}
+
+	return recorder.gzip.Flush()
+}
+
+// Close finishes the current recording. Messages recorded after Close return os.ErrClosed.
+func (recorder *FileRecorder) Close() error {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if !recorder.closed.Swap(true) {
+		close(recorder.done)
+	}
+
+	return recorder.closeFile()
+}
+
+// flushEvery flushes the current recording every interval until the recorder is closed.
+func (recorder *FileRecorder) flushEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			recorder.mu.Lock()
+
+			if recorder.gzip != nil && recorder.err == nil {
+				err := recorder.gzip.Flush()
+				if err != nil {
+					recorder.err = fmt.Errorf("failed to flush recording: %w", err)
+				}
+			}
+
+			recorder.mu.Unlock()
+		case <-recorder.done:
+			return
+		}
+	}
+}
+
+// recordedPayload is the part of a message needed to apply the sample rates.
+type recordedPayload struct {
+	Type string           `json:"t"`
+	Data partitionPayload `json:"d"`
+}
+
+// shouldRecord applies the sample rates to a message.
+func (recorder *FileRecorder) shouldRecord(message []byte) bool {
+	options := &recorder.options
+
+	if options.SampleRate <= 0 && len(options.EventSampleRates) == 0 && options.GuildSampleRate <= 0 {
+		return true
+	}
+
+	var payload recordedPayload
+
+	// Messages that cannot be decoded are always recorded, as they are likely to be of interest.
+	if json.Unmarshal(message, &payload) != nil {
+		return true
+	}
+
+	rate, ok := options.EventSampleRates[payload.Type]
+	if !ok {
+		rate = options.SampleRate
+		if rate <= 0 {
+			rate = 1
+		}
+	}
+
+	if !recorder.sample(rate) {
+		return false
+	}
+
+	if options.GuildSampleRate > 0 && options.GuildSampleRate < 1 {
+		if guildID, ok := payload.Data.guildID(payload.Type); ok {
+			return float64(hashSnowflake(guildID)%10000) < options.GuildSampleRate*10000
+		}
+	}
+
+	return true
+}
+
+// sample returns true for a fraction of calls, spreading them evenly.
+func (recorder *FileRecorder) sample(rate float64) bool {
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	}
+
+	return float64(hashSnowflake(discord.Snowflake(recorder.sampled.Add(1)))%10000) < rate*10000
+}
+
+// shouldRotate returns true if the current recording is full. Recordings are finished once
+// they reach MaxAge by rotateTimer. The caller must hold the lock.
+func (recorder *FileRecorder) shouldRotate() bool {
+	return recorder.options.MaxSize > 0 && recorder.size >= recorder.options.MaxSize
+}
+
+// rotate finishes a recording that has reached MaxAge, if it is still being written to.
+// The next message starts a new recording.
+func (recorder *FileRecorder) rotate(file *os.File) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if recorder.file != file {
+		return
+	}
+
+	err := recorder.closeFile()
+	if err != nil && recorder.err == nil {
+		recorder.err = err
+	}
+}
+
+func (recorder *FileRecorder) openFile() error {
+	now := time.Now().UTC()
+
+	name := fmt.Sprintf("%s-%s.jsonl.gz", recorder.options.Prefix, now.Format("20060102T150405.000000000"))
+
+	file, err := os.OpenFile(filepath.Join(recorder.options.Directory, name), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	recorder.file = file
+	recorder.gzip = gzip.NewWriter(file)
+	recorder.size = 0
+
+	if recorder.options.MaxAge > 0 {
+		recorder.rotateTimer = time.AfterFunc(recorder.options.MaxAge, func() {
+			recorder.rotate(file)
+		})
+	}
+
+	return nil
+}
+
+func (recorder *FileRecorder) closeFile() error {
+	if recorder.gzip == nil {
+		return nil
+	}
+
+	if recorder.rotateTimer != nil {
+		recorder.rotateTimer.Stop()
+		recorder.rotateTimer = nil
+	}
+
+	gzipErr := recorder.gzip.Close()
+	fileErr := recorder.file.Close()
+
+	recorder.gzip = nil
+	recorder.file = nil
+
+	if gzipErr != nil {
+		return fmt.Errorf("failed to finish recording: %w", gzipErr)
+	}
+
+	if fileErr != nil {
+		return fmt.Errorf("failed to close recording: %w", fileErr)
+	}
+
+	return nil
+}
+
+// NewRecordingReader returns a reader of the JSON lines of a recording, decompressing
+// it if it was written by a FileRecorder.
+func NewRecordingReader(reader io.Reader) (io.Reader, error) {
+	bufferedReader := bufio.NewReader(reader)
+
+	magic, err := bufferedReader.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(bufferedReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress recording: %w", err)
+		}
+
+		return gzipReader, nil
+	}
+
+	return bufferedReader, nil
+}
@@ -0,0 +1,313 @@
+package internal_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+)
+
+// memoryRecorder keeps the messages it records.
+type memoryRecorder struct {
+	mu       sync.Mutex
+	messages [][]byte
+}
+
+func (recorder *memoryRecorder) Record(message []byte) error {
+	recorder.mu.Lock()
+	recorder.messages = append(recorder.messages, bytes.Clone(message))
+	recorder.mu.Unlock()
+
+	return nil
+}
+
+// replayRecordings replays every recording in a directory and returns the stats.
+func replayRecordings(t *testing.T, directory string) (sandwich.ReplayStats, int) {
+	t.Helper()
+
+	recordings, err := filepath.Glob(filepath.Join(directory, "*.jsonl.gz"))
+	if err != nil {
+		t.Fatalf("failed to list recordings: %v", err)
+	}
+
+	sandwichClient, _ := newTestBot()
+
+	var total sandwich.ReplayStats
+
+	for _, recording := range recordings {
+		file, err := os.Open(recording)
+		if err != nil {
+			t.Fatalf("failed to open recording: %v", err)
+		}
+
+		stats, err := sandwichClient.Replay(t.Context(), file, sandwich.ReplayOptions{})
+
+		_ = file.Close()
+
+		if err != nil {
+			t.Fatalf("failed to replay %s: %v", filepath.Base(recording), err)
+		}
+
+		total.Read += stats.Read
+		total.Dispatched += stats.Dispatched
+		total.Malformed += stats.Malformed
+	}
+
+	return total, len(recordings)
+}
+
+func TestFileRecorder(t *testing.T) {
+	tests := []struct {
+		name           string
+		options        sandwich.FileRecorderOptions
+		wantRecorded   int
+		wantRecordings int
+	}{
+		{name: "every event", wantRecorded: 4, wantRecordings: 1},
+		{name: "rotates by size", options: sandwich.FileRecorderOptions{MaxSize: 1}, wantRecorded: 4, wantRecordings: 4},
+		{
+			name:           "guild sample rate",
+			options:        sandwich.FileRecorderOptions{GuildSampleRate: 0.0001},
+			wantRecorded:   2,
+			wantRecordings: 1,
+		},
+		{
+			name:           "event sample rate",
+			options:        sandwich.FileRecorderOptions{EventSampleRates: map[string]float64{discord.DiscordEventResumed: 0}},
+			wantRecorded:   2,
+			wantRecordings: 1,
+		},
+	}
+
+	guildID := discord.Snowflake(10)
+
+	messages := [][]byte{
+		marshalPayload(newPayload(discord.DiscordEventResumed, discord.Resume{})),
+		marshalPayload(newPayload(discord.DiscordEventMessageCreate, discord.Message{GuildID: &guildID})),
+		// Messages over multiple lines are compacted.
+		[]byte("{\n\"op\": 0,\n\"t\": \"RESUMED\",\n\"d\": {}\n}"),
+		marshalPayload(newPayload(discord.DiscordEventMessageCreate, discord.Message{GuildID: &guildID})),
+		[]byte("   "),
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.options.Directory = t.TempDir()
+
+			recorder, err := sandwich.NewFileRecorder(test.options)
+			if err != nil {
+				t.Fatalf("failed to create recorder: %v", err)
+			}
+
+			for _, message := range messages {
+				err = recorder.Record(message)
+				if err != nil {
+					t.Fatalf("failed to record: %v", err)
+				}
+			}
+
+			err = recorder.Close()
+			if err != nil {
+				t.Fatalf("failed to close recorder: %v", err)
+			}
+
+			err = recorder.Record(messages[0])
+			if !errors.Is(err, os.ErrClosed) {
+				t.Errorf("Record after Close returned %v, want %v", err, os.ErrClosed)
+			}
+
+			stats, recordings := replayRecordings(t, test.options.Directory)
+
+			if recordings != test.wantRecordings {
+				t.Errorf("wrote %d recordings, want %d", recordings, test.wantRecordings)
+			}
+
+			if stats.Read != test.wantRecorded || stats.Malformed != 0 {
+				t.Errorf("recorded %d messages, %d malformed, want %d", stats.Read, stats.Malformed, test.wantRecorded)
+			}
+		})
+	}
+}
+
+func TestFileRecorderSampleRate(t *testing.T) {
+	directory := t.TempDir()
+
+	recorder, err := sandwich.NewFileRecorder(sandwich.FileRecorderOptions{Directory: directory, SampleRate: 0.25})
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+
+	const messages = 1000
+
+	for range messages {
+		err = recorder.Record(marshalPayload(newPayload(discord.DiscordEventResumed, discord.Resume{})))
+		if err != nil {
+			t.Fatalf("failed to record: %v", err)
+		}
+	}
+
+	err = recorder.Close()
+	if err != nil {
+		t.Fatalf("failed to close recorder: %v", err)
+	}
+
+	stats, _ := replayRecordings(t, directory)
+	if stats.Read < messages*0.2 || stats.Read > messages*0.3 {
+		t.Errorf("recorded %d of %d messages, want about a quarter", stats.Read, messages)
+	}
+}
+
+// readRecording returns what has been written to a recording and if the recording is finished.
+func readRecording(t *testing.T, path string) ([]byte, bool) {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open recording: %v", err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, false
+	}
+
+	// Recordings that are still being written end unexpectedly.
+	data, err := io.ReadAll(reader)
+
+	return data, err == nil
+}
+
+func TestFileRecorderFlushInterval(t *testing.T) {
+	directory := t.TempDir()
+
+	recorder, err := sandwich.NewFileRecorder(sandwich.FileRecorderOptions{Directory: directory, FlushInterval: time.Millisecond * 10})
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+
+	defer recorder.Close()
+
+	message := marshalPayload(newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+	err = recorder.Record(message)
+	if err != nil {
+		t.Fatalf("failed to record: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second * 5)
+
+	for {
+		recordings, _ := filepath.Glob(filepath.Join(directory, "*.jsonl.gz"))
+		if len(recordings) == 1 {
+			if data, _ := readRecording(t, recordings[0]); bytes.Contains(data, message) {
+				break
+			}
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("recorded message was not flushed")
+		}
+
+		time.Sleep(time.Millisecond * 5)
+	}
+}
+
+func TestFileRecorderMaxAge(t *testing.T) {
+	directory := t.TempDir()
+
+	recorder, err := sandwich.NewFileRecorder(sandwich.FileRecorderOptions{Directory: directory, MaxAge: time.Millisecond * 20})
+	if err != nil {
+		t.Fatalf("failed to create recorder: %v", err)
+	}
+
+	message := marshalPayload(newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+	err = recorder.Record(message)
+	if err != nil {
+		t.Fatalf("failed to record: %v", err)
+	}
+
+	// The recording is finished once it reaches MaxAge, without another message being recorded.
+	deadline := time.Now().Add(time.Second * 5)
+
+	for {
+		recordings, _ := filepath.Glob(filepath.Join(directory, "*.jsonl.gz"))
+		if len(recordings) == 1 {
+			if data, finished := readRecording(t, recordings[0]); finished && bytes.Contains(data, message) {
+				break
+			}
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("recording was not finished once it reached MaxAge")
+		}
+
+		time.Sleep(time.Millisecond * 5)
+	}
+
+	err = recorder.Record(message)
+	if err != nil {
+		t.Fatalf("failed to record: %v", err)
+	}
+
+	err = recorder.Close()
+	if err != nil {
+		t.Fatalf("failed to close recorder: %v", err)
+	}
+
+	if _, recordings := replayRecordings(t, directory); recordings != 2 {
+		t.Errorf("wrote %d recordings, want 2", recordings)
+	}
+}
+
+func TestListenToChannelRecords(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	recorder := &memoryRecorder{}
+
+	channel := make(chan []byte)
+	listenErr := make(chan error, 1)
+
+	go func() {
+		listenErr <- harness.Sandwich.ListenToChannel(t.Context(), channel, sandwich.WithRecorder(recorder))
+	}()
+
+	messages := [][]byte{
+		sandwichtest.Marshal(sandwichtest.Resumed(discord.Resume{})),
+		[]byte("not json"),
+	}
+
+	for _, message := range messages {
+		channel <- message
+	}
+
+	harness.Close()
+
+	err := <-listenErr
+	if err != nil {
+		t.Fatalf("ListenToChannel returned %v, want nil", err)
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	if len(recorder.messages) != len(messages) {
+		t.Fatalf("recorded %d messages, want %d", len(recorder.messages), len(messages))
+	}
+
+	for i, message := range messages {
+		if !bytes.Equal(recorder.messages[i], message) {
+			t.Errorf("recorded %q, want %q", recorder.messages[i], message)
+		}
+	}
+}
@@ -0,0 +1,495 @@
+// Package redismq implements a sandwich.AckableMQClient consuming payloads from a Redis
+// stream with a consumer group.
+package redismq
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/internal/mqutil"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrNotConnected      = errors.New("client is not connected")
+	ErrAlreadySubscribed = errors.New("client is already subscribed")
+)
+
+// RetryBackoff is how long the client waits to read again after a read fails, such as
+// whilst Redis is unavailable. It doubles after every failed read, up to MaxRetryBackoff.
+var (
+	RetryBackoff    = time.Second
+	MaxRetryBackoff = time.Second * 30
+)
+
+var _ sandwich.AckableMQClient = (*Client)(nil)
+
+// Client consumes a Redis stream as a member of a consumer group. Every client in the
+// same group shares the messages of the stream, and a consumer that restarts receives
+// the messages it did not acknowledge before anything new.
+//
+// Messages are received from either Messages or Chan, not both. Messages read from
+// Messages must be acknowledged, such as by Sandwich.ListenToMessages, whereas messages
+// read from Chan are acknowledged as soon as they are read.
+type Client struct {
+	// Logger reports stream entries that are acknowledged without being delivered,
+	// as they do not contain a payload.
+	Logger *slog.Logger
+
+	mu sync.Mutex
+
+	redis *redis.Client
+
+	group     string
+	consumer  string
+	field     string
+	count     int64
+	block     time.Duration
+	claimIdle time.Duration
+
+	channel string
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	// unsubscribed is closed by Unsubscribe to stop forwarding messages to Chan.
+	unsubscribed chan struct{}
+
+	messages chan *sandwich.MQMessage
+
+	// chanData is created by Chan. Whilst subscribed, messages are forwarded to it.
+	chanData chan []byte
+}
+
+// NewClient creates a Client. Connect must be called before Subscribe.
+func NewClient() *Client {
+	return &Client{
+		Logger:   slog.Default(),
+		mu:       sync.Mutex{},
+		messages: make(chan *sandwich.MQMessage),
+	}
+}
+
+func (c *Client) String() string {
+	return "redis"
+}
+
+// Channel returns the stream the client is subscribed to.
+func (c *Client) Channel() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.channel
+}
+
+// Connect connects to Redis. Connections that are lost are reopened when the stream is
+// next read. The following args are supported:
+//
+//   - Address: a redis:// URL, or the host and port of the server. Defaults to localhost:6379.
+//   - Group: the name of the consumer group. Defaults to clientName.
+//   - Consumer: the name of the consumer within the group, which should stay the same when
+//     the client restarts. Defaults to clientName followed by the hostname.
+//   - Field: the field of each stream entry containing the payload. Defaults to "data".
+//   - Count: how many messages are read at once. Defaults to 64.
+//   - Block: how long a read waits for new messages, as a duration string or seconds. Defaults to 5 seconds.
+//   - ClaimIdle: how long until a message that was not acknowledged is claimed and delivered
+//     again. A negative value never claims messages. Defaults to 30 seconds.
+func (c *Client) Connect(ctx context.Context, clientName string, args map[string]any) error {
+	address, err := mqutil.StringArg(args, "Address", "localhost:6379")
+	if err != nil {
+		return err
+	}
+
+	hostname, _ := os.Hostname()
+
+	group, err := mqutil.StringArg(args, "Group", clientName)
+	if err != nil {
+		return err
+	}
+
+	consumer, err := mqutil.StringArg(args, "Consumer", strings.Trim(clientName+"-"+hostname, "-"))
+	if err != nil {
+		return err
+	}
+
+	field, err := mqutil.StringArg(args, "Field", "data")
+	if err != nil {
+		return err
+	}
+
+	count, err := mqutil.IntArg(args, "Count", 64)
+	if err != nil {
+		return err
+	}
+
+	block, err := mqutil.DurationArg(args, "Block", time.Second*5)
+	if err != nil {
+		return err
+	}
+
+	claimIdle, err := mqutil.DurationArg(args, "ClaimIdle", time.Second*30)
+	if err != nil {
+		return err
+	}
+
+	var options *redis.Options
+
+	if strings.Contains(address, "://") {
+		options, err = redis.ParseURL(address)
+		if err != nil {
+			return fmt.Errorf("failed to parse redis address: %w", err)
+		}
+	} else {
+		options = &redis.Options{Addr: address}
+	}
+
+	options.ClientName = clientName
+
+	client := redis.NewClient(options)
+
+	err = client.Ping(ctx).Err()
+	if err != nil {
+		_ = client.Close()
+
+		return fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.redis != nil {
+		_ = c.redis.Close()
+	}
+
+	c.redis = client
+	c.group = group
+	c.consumer = consumer
+	c.field = field
+	c.count = int64(count)
+	c.block = block
+	c.claimIdle = claimIdle
+
+	return nil
+}
+
+// Subscribe creates the consumer group of the stream, if it does not exist, and starts
+// receiving its messages. New groups only receive messages added after they are created.
+func (c *Client) Subscribe(ctx context.Context, channel string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.redis == nil {
+		return ErrNotConnected
+	}
+
+	if c.cancel != nil {
+		return ErrAlreadySubscribed
+	}
+
+	err := c.createGroup(ctx, c.redis, channel)
+	if err != nil {
+		return err
+	}
+
+	consumeCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		c.consume(consumeCtx, c.redis, channel)
+	}()
+
+	c.channel = channel
+	c.cancel = cancel
+	c.done = done
+	c.unsubscribed = make(chan struct{})
+
+	if c.chanData != nil {
+		go c.forward(c.chanData, c.unsubscribed)
+	}
+
+	return nil
+}
+
+// Unsubscribe stops receiving messages. Messages that were read but not acknowledged stay
+// pending, so they are delivered again once subscribed.
+func (c *Client) Unsubscribe(_ context.Context) {
+	c.mu.Lock()
+	cancel, done := c.cancel, c.done
+	c.cancel = nil
+	c.done = nil
+	c.channel = ""
+
+	if c.unsubscribed != nil {
+		close(c.unsubscribed)
+		c.unsubscribed = nil
+	}
+
+	c.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-done
+}
+
+// Messages returns the channel messages are delivered to. Every message must be
+// acknowledged or it is delivered again once ClaimIdle has passed.
+//
+// Negatively acknowledging a message adds its entry to the end of the stream again and
+// acknowledges the original, so it is delivered again once the messages added since have
+// been read, without waiting for ClaimIdle. Other consumer groups reading the stream also
+// receive the added entry.
+func (c *Client) Messages() <-chan *sandwich.MQMessage {
+	return c.messages
+}
+
+// Chan returns a channel of the data of every message. Messages are acknowledged as soon
+// as they are read, so they are lost if they are not handled. Messages are forwarded
+// to it whilst subscribed.
+func (c *Client) Chan() chan []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.chanData == nil {
+		c.chanData = make(chan []byte)
+
+		if c.unsubscribed != nil {
+			go c.forward(c.chanData, c.unsubscribed)
+		}
+	}
+
+	return c.chanData
+}
+
+// forward sends the data of messages to the channel returned by Chan, acknowledging them
+// once they are read, until unsubscribed.
+func (c *Client) forward(chanData chan<- []byte, unsubscribed <-chan struct{}) {
+	for {
+		select {
+		case message := <-c.messages:
+			select {
+			case chanData <- message.Data:
+				_ = message.Ack(context.Background())
+			case <-unsubscribed:
+				_ = message.Nack(context.Background())
+
+				return
+			}
+		case <-unsubscribed:
+			return
+		}
+	}
+}
+
+// Close unsubscribes and closes the connection to Redis.
+func (c *Client) Close() error {
+	c.Unsubscribe(context.Background())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.redis == nil {
+		return nil
+	}
+
+	err := c.redis.Close()
+	c.redis = nil
+
+	return err
+}
+
+func (c *Client) createGroup(ctx context.Context, client *redis.Client, stream string) error {
+	err := client.XGroupCreateMkStream(ctx, stream, c.group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	return nil
+}
+
+// consume delivers messages of the stream until the context is done. Messages left pending
+// by a previous run of the consumer are delivered first.
+func (c *Client) consume(ctx context.Context, client *redis.Client, stream string) {
+	var (
+		// pendingID is the ID after which to read pending messages, or ">" once there are none.
+		pendingID = "0"
+		nextClaim time.Time
+		backoff   = RetryBackoff
+	)
+
+	for ctx.Err() == nil {
+		var (
+			messages []redis.XMessage
+			err      error
+		)
+
+		switch {
+		case pendingID != ">":
+			messages, err = c.read(ctx, client, stream, pendingID)
+			if err == nil {
+				if len(messages) == 0 {
+					pendingID = ">"
+				} else {
+					pendingID = messages[len(messages)-1].ID
+				}
+			}
+		case c.claimIdle >= 0 && !time.Now().Before(nextClaim):
+			messages, err = c.claim(ctx, client, stream)
+
+			// Keep claiming whilst there are more messages to claim.
+			if int64(len(messages)) < c.count {
+				nextClaim = time.Now().Add(max(c.claimIdle/2, time.Second))
+			}
+		default:
+			messages, err = c.read(ctx, client, stream, ">")
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			// The group is lost if the stream was deleted.
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				_ = c.createGroup(ctx, client, stream)
+			}
+
+			if !mqutil.SleepContext(ctx, backoff) {
+				return
+			}
+
+			backoff = min(backoff*2, MaxRetryBackoff)
+
+			continue
+		}
+
+		backoff = RetryBackoff
+
+		for _, message := range messages {
+			if !c.deliver(ctx, client, stream, message) {
+				return
+			}
+		}
+	}
+}
+
+// read reads messages of the consumer group, starting after the ID.
+func (c *Client) read(ctx context.Context, client *redis.Client, stream, id string) ([]redis.XMessage, error) {
+	block := c.block
+	if id != ">" {
+		// Pending messages are returned straight away.
+		block = -1
+	}
+
+	streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    c.group,
+		Consumer: c.consumer,
+		Streams:  []string{stream, id},
+		Count:    c.count,
+		Block:    block,
+	}).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	var messages []redis.XMessage
+	for _, stream := range streams {
+		messages = append(messages, stream.Messages...)
+	}
+
+	return messages, nil
+}
+
+// claim claims messages of any consumer in the group that have not been acknowledged
+// within ClaimIdle.
+func (c *Client) claim(ctx context.Context, client *redis.Client, stream string) ([]redis.XMessage, error) {
+	messages, _, err := client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    c.group,
+		MinIdle:  c.claimIdle,
+		Start:    "0-0",
+		Count:    c.count,
+		Consumer: c.consumer,
+	}).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to claim messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// deliver sends a message to the messages channel. Entries without a payload in the
+// field are acknowledged, as they would never be handled. Returns false if the context
+// is done first.
+func (c *Client) deliver(ctx context.Context, client *redis.Client, stream string, message redis.XMessage) bool {
+	value, ok := message.Values[c.field]
+	if !ok {
+		c.discard(ctx, client, stream, message.ID, "Acknowledged stream entry without a payload")
+
+		return true
+	}
+
+	data, ok := value.(string)
+	if !ok {
+		c.discard(ctx, client, stream, message.ID, "Acknowledged stream entry with a payload that is not a string",
+			"type", fmt.Sprintf("%T", value))
+
+		return true
+	}
+
+	id := message.ID
+
+	select {
+	case c.messages <- &sandwich.MQMessage{
+		Data: []byte(data),
+		Ack: func(ctx context.Context) error {
+			return client.XAck(ctx, stream, c.group, id).Err()
+		},
+		Nack: func(ctx context.Context) error {
+			return c.requeue(ctx, client, stream, message)
+		},
+	}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// discard acknowledges an entry that is not delivered and logs why.
+func (c *Client) discard(ctx context.Context, client *redis.Client, stream, id, reason string, args ...any) {
+	err := client.XAck(ctx, stream, c.group, id).Err()
+
+	c.Logger.Warn(reason, append([]any{"stream", stream, "id", id, "field", c.field, "error", err}, args...)...)
+}
+
+// requeue adds an entry to the end of the stream again and acknowledges the original.
+func (c *Client) requeue(ctx context.Context, client *redis.Client, stream string, message redis.XMessage) error {
+	_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: stream,
+			Values: message.Values,
+		})
+		pipe.XAck(ctx, stream, c.group, message.ID)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to requeue message: %w", err)
+	}
+
+	return nil
+}
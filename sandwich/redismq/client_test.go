@@ -0,0 +1,313 @@
+package redismq_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	"github.com/alicebob/miniredis/v2"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/redismq"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+	"github.com/redis/go-redis/v9"
+)
+
+// These tests run against miniredis, or the Redis server at SANDWICH_TEST_REDIS_ADDRESS if it is set.
+const addressEnv = "SANDWICH_TEST_REDIS_ADDRESS"
+
+// redisAddress returns the address of the Redis server to test against.
+func redisAddress(t *testing.T) string {
+	t.Helper()
+
+	if address := os.Getenv(addressEnv); address != "" {
+		return address
+	}
+
+	return miniredis.RunT(t).Addr()
+}
+
+// newStream returns a stream name for the test and a client to add to it.
+func newStream(t *testing.T, address string) (*redis.Client, string) {
+	t.Helper()
+
+	options := &redis.Options{Addr: address}
+
+	if parsed, err := redis.ParseURL(address); err == nil {
+		options = parsed
+	}
+
+	client := redis.NewClient(options)
+
+	stream := fmt.Sprintf("sandwich_test_%d", time.Now().UnixNano())
+
+	t.Cleanup(func() {
+		_ = client.Del(context.Background(), stream).Err()
+		_ = client.Close()
+	})
+
+	return client, stream
+}
+
+func add(t *testing.T, client *redis.Client, stream string, data []byte) {
+	t.Helper()
+
+	err := client.XAdd(t.Context(), &redis.XAddArgs{
+		Stream: stream,
+		Values: map[string]any{"data": data},
+	}).Err()
+	if err != nil {
+		t.Fatalf("failed to add to stream: %v", err)
+	}
+}
+
+func newClient(t *testing.T, address, stream string) *redismq.Client {
+	t.Helper()
+
+	client := redismq.NewClient()
+
+	subscribe(t, client, address, stream)
+
+	return client
+}
+
+// subscribe connects a client and subscribes it to the stream. The client is closed when the test ends.
+func subscribe(t *testing.T, client *redismq.Client, address, stream string) {
+	t.Helper()
+
+	err := client.Connect(t.Context(), "sandwich-test", map[string]any{
+		"Address":   address,
+		"Block":     "100ms",
+		"ClaimIdle": "1s",
+	})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	err = client.Subscribe(t.Context(), stream)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+}
+
+// pending returns how many messages of the stream have not been acknowledged.
+func pending(t *testing.T, client *redis.Client, stream string) int64 {
+	t.Helper()
+
+	pending, err := client.XPending(t.Context(), stream, "sandwich-test").Result()
+	if err != nil {
+		t.Fatalf("failed to get pending messages: %v", err)
+	}
+
+	return pending.Count
+}
+
+func receive(t *testing.T, client *redismq.Client) *sandwich.MQMessage {
+	t.Helper()
+
+	select {
+	case message := <-client.Messages():
+		return message
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for message")
+
+		return nil
+	}
+}
+
+func TestClientRedeliversNackedMessages(t *testing.T) {
+	address := redisAddress(t)
+
+	redisClient, stream := newStream(t, address)
+	client := newClient(t, address, stream)
+
+	add(t, redisClient, stream, []byte("payload"))
+
+	message := receive(t, client)
+	if string(message.Data) != "payload" {
+		t.Fatalf("received %q, want %q", message.Data, "payload")
+	}
+
+	err := message.Nack(t.Context())
+	if err != nil {
+		t.Fatalf("failed to nack: %v", err)
+	}
+
+	// The entry is added again and the original is acknowledged.
+	if length := redisClient.XLen(t.Context(), stream).Val(); length != 2 {
+		t.Errorf("stream has %d entries after nack, want 2", length)
+	}
+
+	if count := pending(t, redisClient, stream); count != 0 {
+		t.Errorf("%d messages are pending after nack, want 0", count)
+	}
+
+	message = receive(t, client)
+	if string(message.Data) != "payload" {
+		t.Fatalf("received %q after nack, want %q", message.Data, "payload")
+	}
+
+	err = message.Ack(t.Context())
+	if err != nil {
+		t.Fatalf("failed to ack: %v", err)
+	}
+
+	select {
+	case message := <-client.Messages():
+		t.Fatalf("received %q after ack", message.Data)
+	case <-time.After(time.Second * 2):
+	}
+}
+
+func TestClientReceivesPendingMessagesAfterRestart(t *testing.T) {
+	address := redisAddress(t)
+
+	redisClient, stream := newStream(t, address)
+	client := newClient(t, address, stream)
+
+	add(t, redisClient, stream, []byte("payload"))
+
+	// The message is read but never acknowledged.
+	receive(t, client)
+
+	err := client.Close()
+	if err != nil {
+		t.Fatalf("failed to close: %v", err)
+	}
+
+	client = newClient(t, address, stream)
+
+	message := receive(t, client)
+	if string(message.Data) != "payload" {
+		t.Fatalf("received %q after restart, want %q", message.Data, "payload")
+	}
+}
+
+func TestClientChan(t *testing.T) {
+	address := redisAddress(t)
+
+	redisClient, stream := newStream(t, address)
+	client := newClient(t, address, stream)
+
+	// Messages are forwarded to Chan again once subscribed again.
+	for _, data := range []string{"first", "second"} {
+		add(t, redisClient, stream, []byte(data))
+
+		select {
+		case received := <-client.Chan():
+			if string(received) != data {
+				t.Fatalf("received %q, want %q", received, data)
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out waiting for message")
+		}
+
+		client.Unsubscribe(t.Context())
+
+		err := client.Subscribe(t.Context(), stream)
+		if err != nil {
+			t.Fatalf("failed to subscribe: %v", err)
+		}
+	}
+}
+
+func TestListenToMessages(t *testing.T) {
+	address := redisAddress(t)
+
+	redisClient, stream := newStream(t, address)
+	client := newClient(t, address, stream)
+
+	harness := sandwichtest.New(t)
+
+	received := make(chan discord.Message, 1)
+
+	harness.Bot.RegisterOnMessageCreateEvent(func(_ *sandwich.EventContext, message discord.Message) error {
+		received <- message
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go func() {
+		_ = harness.Sandwich.ListenToMessages(ctx, client.Messages())
+	}()
+
+	add(t, redisClient, stream, sandwichtest.Marshal(sandwichtest.MessageCreate(discord.Message{
+		ID:        1,
+		ChannelID: 2,
+		Content:   "hello",
+	})))
+
+	select {
+	case message := <-received:
+		if message.Content != "hello" {
+			t.Fatalf("received %q, want %q", message.Content, "hello")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for MESSAGE_CREATE")
+	}
+
+	// The message is acknowledged once the listener returns.
+	deadline := time.Now().Add(time.Second * 5)
+
+	for {
+		count := pending(t, redisClient, stream)
+		if count == 0 {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("message was not acknowledged, %d pending", count)
+		}
+
+		time.Sleep(time.Millisecond * 50)
+	}
+}
+
+func TestClientAcksEntriesWithoutPayload(t *testing.T) {
+	address := redisAddress(t)
+
+	redisClient, stream := newStream(t, address)
+
+	logs := &bytes.Buffer{}
+
+	client := redismq.NewClient()
+	client.Logger = slog.New(slog.NewTextHandler(logs, nil))
+
+	subscribe(t, client, address, stream)
+
+	err := redisClient.XAdd(t.Context(), &redis.XAddArgs{
+		Stream: stream,
+		Values: map[string]any{"other": "payload"},
+	}).Err()
+	if err != nil {
+		t.Fatalf("failed to add to stream: %v", err)
+	}
+
+	add(t, redisClient, stream, []byte("payload"))
+
+	// The entry without a payload is acknowledged before the next message is delivered.
+	message := receive(t, client)
+	if string(message.Data) != "payload" {
+		t.Fatalf("received %q, want %q", message.Data, "payload")
+	}
+
+	if count := pending(t, redisClient, stream); count != 1 {
+		t.Errorf("%d messages are pending, want 1", count)
+	}
+
+	if !strings.Contains(logs.String(), "without a payload") {
+		t.Errorf("entry without a payload was not logged:\n%s", logs.String())
+	}
+}
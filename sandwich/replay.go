@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+)
+
+// MaxReplayLineSize is the largest payload that can be read by Replay.
+var MaxReplayLineSize = 16 * 1024 * 1024
+
+// ReplayOptions controls how recorded payloads are replayed.
+type ReplayOptions struct {
+	// RealTime waits between payloads for as long as there was between them when they were
+	// published, using the publish time in their trace. Otherwise payloads are replayed as
+	// fast as possible.
+	RealTime bool
+
+	// Speed scales the waits of RealTime, such as 2 to replay twice as fast.
+	// A value of 0 or less replays at the recorded speed.
+	Speed float64
+
+	// EventTypes only replays events of these types, if set.
+	EventTypes []string
+
+	// GuildIDs only replays events belonging to these guilds, if set.
+	GuildIDs []discord.Snowflake
+
+	// StopOnFirstError dispatches each payload on the current goroutine, waiting for every
+	// listener to finish, and stops replaying once the parser or a listener returns an error.
+	StopOnFirstError bool
+}
+
+// ReplayStats counts the payloads read by Replay. Payloads without a registered bot
+// are counted as Ignored.
+type ReplayStats struct {
+	Read       int
+	Dispatched int
+	Ignored    int
+	Skipped    int
+	Malformed  int
+}
+
+// Replay reads payloads from a JSON lines stream of sandwich_daemon.ProducedPayload, such as a
+// recording written by a FileRecorder, and dispatches them to the registered bots as if they had been received.
+// Replay returns once the stream ends, the context is done or, if StopOnFirstError is set, a
+// payload could not be handled.
+func (sandwich *Sandwich) Replay(ctx context.Context, reader io.Reader, options ReplayOptions) (stats ReplayStats, err error) {
+	reader, err = NewRecordingReader(reader)
+	if err != nil {
+		return stats, err
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxReplayLineSize)
+
+	var (
+		startedAt      time.Time
+		firstPublished time.Time
+	)
+
+	for line := 1; scanner.Scan(); line++ {
+		if ctx.Err() != nil {
+			return stats, ctx.Err()
+		}
+
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		stats.Read++
+
+		var payload sandwich_daemon.ProducedPayload
+
+		err = json.Unmarshal(data, &payload)
+		if err != nil {
+			stats.Malformed++
+
+			if options.StopOnFirstError {
+				return stats, fmt.Errorf("failed to unmarshal payload on line %d: %w", line, err)
+			}
+
+			sandwich.Logger.Warn("Failed to unmarshal replayed payload", "line", line, "error", err)
+
+			continue
+		}
+
+		if !options.matches(&payload) {
+			stats.Skipped++
+
+			continue
+		}
+
+		if options.RealTime {
+			if published, ok := payloadPublishedAt(&payload); ok {
+				if startedAt.IsZero() {
+					startedAt, firstPublished = time.Now(), published
+				}
+
+				speed := options.Speed
+				if speed <= 0 {
+					speed = 1
+				}
+
+				wait := time.Until(startedAt.Add(time.Duration(float64(published.Sub(firstPublished)) / speed)))
+				if wait > 0 && !sleepContext(ctx, wait) {
+					return stats, ctx.Err()
+				}
+			}
+		}
+
+		if options.StopOnFirstError {
+			result, err := sandwich.DispatchProducedPayloadSync(ctx, payload)
+			if err != nil {
+				return stats, fmt.Errorf("failed to dispatch payload on line %d: %w", line, err)
+			}
+
+			if result.Ignored {
+				stats.Ignored++
+
+				continue
+			}
+
+			stats.Dispatched++
+
+			if len(result.Errors) > 0 {
+				return stats, fmt.Errorf("failed to handle payload on line %d: %w", line, result.Errors[0])
+			}
+		} else {
+			bot, err := sandwich.payloadBot(payload)
+
+			switch {
+			case err != nil:
+				sandwich.Logger.Warn("Failed to dispatch replayed payload", "line", line, "error", err)
+			case bot == nil:
+				stats.Ignored++
+			default:
+				bot.Dispatch(sandwich.newEventContext(ctx, bot.Handlers, &payload), payload)
+
+				stats.Dispatched++
+			}
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return stats, fmt.Errorf("failed to read payloads: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (options *ReplayOptions) matches(payload *sandwich_daemon.ProducedPayload) bool {
+	if len(options.EventTypes) > 0 && !slices.Contains(options.EventTypes, payload.Type) {
+		return false
+	}
+
+	if len(options.GuildIDs) > 0 {
+		guildID, ok, err := payloadGuildID(payload)
+		if err != nil || !ok || !slices.Contains(options.GuildIDs, guildID) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// payloadPublishedAt returns when a payload was published by Sandwich Daemon, from its trace.
+func payloadPublishedAt(payload *sandwich_daemon.ProducedPayload) (time.Time, bool) {
+	for _, key := range []string{"publish", "dispatch"} {
+		if nanoseconds, ok := payload.Trace[key].(float64); ok && nanoseconds > 0 {
+			return time.Unix(0, int64(nanoseconds)), true
+		}
+	}
+
+	return time.Time{}, false
+}
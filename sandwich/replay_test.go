@@ -0,0 +1,165 @@
+package internal_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+// newRecording returns a recording of a RESUMED event, a MESSAGE_CREATE event in guilds
+// 10 and 11 and a malformed line.
+func newRecording(t *testing.T) []byte {
+	t.Helper()
+
+	guildA, guildB := discord.Snowflake(10), discord.Snowflake(11)
+
+	var recording bytes.Buffer
+
+	for i, payload := range []any{
+		newPayload(discord.DiscordEventResumed, discord.Resume{}),
+		newPayload(discord.DiscordEventMessageCreate, discord.Message{GuildID: &guildA}),
+		newPayload(discord.DiscordEventMessageCreate, discord.Message{GuildID: &guildB}),
+	} {
+		line, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("failed to marshal payload: %v", err)
+		}
+
+		recording.Write(line)
+		recording.WriteString("\n")
+
+		if i == 1 {
+			recording.WriteString("\n")
+		}
+	}
+
+	recording.WriteString("not json\n")
+
+	return recording.Bytes()
+}
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     sandwich.ReplayOptions
+		want        sandwich.ReplayStats
+		wantHandled int64
+		wantErr     string
+	}{
+		{
+			name:        "everything",
+			want:        sandwich.ReplayStats{Read: 4, Dispatched: 3, Malformed: 1},
+			wantHandled: 3,
+		},
+		{
+			name:        "event types",
+			options:     sandwich.ReplayOptions{EventTypes: []string{discord.DiscordEventMessageCreate}},
+			want:        sandwich.ReplayStats{Read: 4, Dispatched: 2, Skipped: 1, Malformed: 1},
+			wantHandled: 2,
+		},
+		{
+			name:        "guild ids",
+			options:     sandwich.ReplayOptions{GuildIDs: []discord.Snowflake{10}},
+			want:        sandwich.ReplayStats{Read: 4, Dispatched: 1, Skipped: 2, Malformed: 1},
+			wantHandled: 1,
+		},
+		{
+			name:        "stop on malformed line",
+			options:     sandwich.ReplayOptions{StopOnFirstError: true},
+			want:        sandwich.ReplayStats{Read: 4, Dispatched: 3, Malformed: 1},
+			wantHandled: 3,
+			wantErr:     "failed to unmarshal payload on line 5",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sandwichClient, bot := newTestBot()
+
+			var handled atomic.Int64
+
+			bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+				handled.Add(1)
+
+				return nil
+			})
+
+			bot.Handlers.RegisterOnMessageCreateEvent(func(*sandwich.EventContext, discord.Message) error {
+				handled.Add(1)
+
+				return nil
+			})
+
+			stats, err := sandwichClient.Replay(t.Context(), bytes.NewReader(newRecording(t)), test.options)
+			if test.wantErr == "" && err != nil {
+				t.Fatalf("failed to replay: %v", err)
+			}
+
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("Replay returned %v, want %q", err, test.wantErr)
+			}
+
+			if stats != test.want {
+				t.Errorf("Replay returned %+v, want %+v", stats, test.want)
+			}
+
+			_, err = bot.Handlers.Close(t.Context())
+			if err != nil {
+				t.Fatalf("failed to close handlers: %v", err)
+			}
+
+			if got := handled.Load(); got != test.wantHandled {
+				t.Errorf("handled %d events, want %d", got, test.wantHandled)
+			}
+		})
+	}
+}
+
+func TestReplayStopOnFirstError(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	bot.Handlers.RegisterOnMessageCreateEvent(func(*sandwich.EventContext, discord.Message) error {
+		return errTest
+	})
+
+	stats, err := sandwichClient.Replay(t.Context(), bytes.NewReader(newRecording(t)), sandwich.ReplayOptions{StopOnFirstError: true})
+	if !errors.Is(err, errTest) {
+		t.Fatalf("Replay returned %v, want %v", err, errTest)
+	}
+
+	if want := (sandwich.ReplayStats{Read: 2, Dispatched: 2}); stats != want {
+		t.Errorf("Replay returned %+v, want %+v", stats, want)
+	}
+}
+
+func TestReplayIgnored(t *testing.T) {
+	unknown := newPayload(discord.DiscordEventResumed, discord.Resume{})
+	unknown.Metadata.Identifier = "unknown"
+
+	recording := bytes.Join([][]byte{
+		marshalPayload(unknown),
+		marshalPayload(newPayload(discord.DiscordEventResumed, discord.Resume{})),
+	}, []byte("\n"))
+
+	for _, stopOnFirstError := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stop on first error %v", stopOnFirstError), func(t *testing.T) {
+			sandwichClient, _ := newTestBot()
+
+			stats, err := sandwichClient.Replay(t.Context(), bytes.NewReader(recording), sandwich.ReplayOptions{StopOnFirstError: stopOnFirstError})
+			if err != nil {
+				t.Fatalf("failed to replay: %v", err)
+			}
+
+			if want := (sandwich.ReplayStats{Read: 2, Dispatched: 1, Ignored: 1}); stats != want {
+				t.Errorf("Replay returned %+v, want %+v", stats, want)
+			}
+		})
+	}
+}
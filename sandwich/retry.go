@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy decides if and when a listener that returned an error is called again.
+// Retries are scheduled in the background so they do not hold up the worker queue.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a listener is called, including the first call.
+	// A value of 1 or less disables retries.
+	MaxAttempts int
+
+	// InitialBackoff is how long to wait before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between retries. A value of 0 leaves it uncapped.
+	MaxBackoff time.Duration
+
+	// Multiplier is applied to the backoff after every retry. Values below 1 are treated as 1.
+	Multiplier float64
+
+	// Jitter randomises each backoff by up to this fraction of it, between 0 and 1.
+	Jitter float64
+
+	// Classifier returns true if the error should be retried. If nil, every error
+	// except panics is retried.
+	Classifier func(err error) bool
+}
+
+// NewRetryPolicy creates a RetryPolicy with exponential backoff.
+func NewRetryPolicy(maxAttempts int, initialBackoff, maxBackoff time.Duration) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     maxBackoff,
+		Multiplier:     2,
+		Jitter:         0.2,
+		Classifier:     nil,
+	}
+}
+
+// SetClassifier sets the func deciding which errors are retried.
+func (p *RetryPolicy) SetClassifier(classifier func(err error) bool) *RetryPolicy {
+	p.Classifier = classifier
+
+	return p
+}
+
+// ShouldRetry returns true if a listener that failed with err on the given attempt,
+// starting at 1, should be called again.
+func (p *RetryPolicy) ShouldRetry(err error, attempt int) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	if p.Classifier == nil {
+		var panicErr *PanicError
+
+		return !errors.As(err, &panicErr)
+	}
+
+	return p.Classifier(err)
+}
+
+// Backoff returns how long to wait after the given attempt, starting at 1, before retrying.
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+
+	multiplier := max(p.Multiplier, 1)
+	for range attempt - 1 {
+		backoff *= multiplier
+
+		if p.MaxBackoff > 0 && backoff >= float64(p.MaxBackoff) {
+			break
+		}
+	}
+
+	if p.MaxBackoff > 0 {
+		backoff = min(backoff, float64(p.MaxBackoff))
+	}
+
+	if jitter := min(max(p.Jitter, 0), 1); jitter > 0 {
+		backoff += backoff * jitter * (rand.Float64()*2 - 1) //nolint:gosec
+	}
+
+	return time.Duration(backoff)
+}
+
+// SetRetryPolicy sets the retry policy for listeners of a specific event type.
+// Listeners with their own retry policy ignore it. A nil policy disables retries.
+func (h *Handlers) SetRetryPolicy(eventName string, policy *RetryPolicy) {
+	h.retryPoliciesMu.Lock()
+	h.RetryPolicies[eventName] = policy
+	h.retryPoliciesMu.Unlock()
+}
+
+// GetRetryPolicy returns the retry policy for listeners of a specific event type.
+func (h *Handlers) GetRetryPolicy(eventName string) *RetryPolicy {
+	h.retryPoliciesMu.RLock()
+	policy := h.RetryPolicies[eventName]
+	h.retryPoliciesMu.RUnlock()
+
+	return policy
+}
+
+// listenerRetryPolicy returns the retry policy of a listener, falling back to the
+// retry policy of the event type.
+func (h *Handlers) listenerRetryPolicy(eventName string, listener *EventListener) *RetryPolicy {
+	if listener.RetryPolicy != nil {
+		return listener.RetryPolicy
+	}
+
+	return h.GetRetryPolicy(eventName)
+}
+
+// scheduleRetry calls retry after delay in the background, or abandon if the handlers are
+// closed first. Returns false if the handlers are closed and no more retries are accepted,
+// in which case the retry is counted as abandoned.
+func (h *Handlers) scheduleRetry(delay time.Duration, retry, abandon func()) bool {
+	h.retriesMu.Lock()
+	defer h.retriesMu.Unlock()
+
+	if h.retriesStopped {
+		h.abandonedRetries.Add(1)
+
+		return false
+	}
+
+	if h.retryStop == nil {
+		h.retryStop = make(chan struct{})
+	}
+
+	stop := h.retryStop
+
+	h.retriesWg.Go(func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			retry()
+		case <-stop:
+			h.abandonedRetries.Add(1)
+
+			abandon()
+		}
+	})
+
+	return true
+}
+
+// stopRetries cancels retries that are waiting and stops new retries from being scheduled.
+// Returns a channel closed once retries that are already running have finished.
+func (h *Handlers) stopRetries() <-chan struct{} {
+	h.retriesMu.Lock()
+
+	if !h.retriesStopped {
+		h.retriesStopped = true
+
+		if h.retryStop != nil {
+			close(h.retryStop)
+		}
+	}
+
+	h.retriesMu.Unlock()
+
+	stopped := make(chan struct{})
+
+	go func() {
+		h.retriesWg.Wait()
+		close(stopped)
+	}()
+
+	return stopped
+}
+
+// sleepContext waits for the duration, returning false if the context is done first.
+func sleepContext(ctx context.Context, duration time.Duration) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
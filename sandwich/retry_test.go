@@ -0,0 +1,254 @@
+package internal_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+)
+
+func TestRetryPolicies(t *testing.T) {
+	tests := []struct {
+		name           string
+		eventPolicy    *sandwich.RetryPolicy
+		listenerPolicy *sandwich.RetryPolicy
+		failures       int
+		wantCalls      int
+		wantAttempts   int
+	}{
+		{name: "no policy", failures: 1, wantCalls: 1, wantAttempts: 1},
+		{name: "succeeds on retry", listenerPolicy: sandwich.NewRetryPolicy(3, time.Millisecond, 0), failures: 2, wantCalls: 3},
+		{name: "exhausted", listenerPolicy: sandwich.NewRetryPolicy(3, time.Millisecond, 0), failures: 5, wantCalls: 3, wantAttempts: 3},
+		{name: "event policy", eventPolicy: sandwich.NewRetryPolicy(2, time.Millisecond, 0), failures: 5, wantCalls: 2, wantAttempts: 2},
+		{
+			name:           "listener policy overrides event policy",
+			eventPolicy:    sandwich.NewRetryPolicy(2, time.Millisecond, 0),
+			listenerPolicy: sandwich.NewRetryPolicy(4, time.Millisecond, 0),
+			failures:       5,
+			wantCalls:      4,
+			wantAttempts:   4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sandwichClient, bot := newTestBot()
+			bot.Handlers.SetRetryPolicy(discord.DiscordEventResumed, test.eventPolicy)
+
+			nextHandlerError := receiveHandlerError(t, bot)
+			succeeded := make(chan struct{})
+
+			var calls atomic.Int32
+
+			bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+				if int(calls.Add(1)) <= test.failures {
+					return errTest
+				}
+
+				close(succeeded)
+
+				return nil
+			}).SetRetryPolicy(test.listenerPolicy)
+
+			dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+			if test.wantAttempts > 0 {
+				if handlerErr := nextHandlerError(); handlerErr.Attempts != test.wantAttempts {
+					t.Errorf("ERROR handler received an error after %d attempts, want %d", handlerErr.Attempts, test.wantAttempts)
+				}
+			} else {
+				select {
+				case <-succeeded:
+				case <-time.After(time.Second * 5):
+					t.Fatal("listener did not succeed")
+				}
+			}
+
+			if calls := int(calls.Load()); calls != test.wantCalls {
+				t.Errorf("listener was called %d times, want %d", calls, test.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *sandwich.RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{name: "first", policy: &sandwich.RetryPolicy{InitialBackoff: time.Second, Multiplier: 2}, attempt: 1, want: time.Second},
+		{name: "doubles", policy: &sandwich.RetryPolicy{InitialBackoff: time.Second, Multiplier: 2}, attempt: 3, want: time.Second * 4},
+		{name: "capped", policy: &sandwich.RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Second * 3, Multiplier: 2}, attempt: 10, want: time.Second * 3},
+		{name: "multiplier below one", policy: &sandwich.RetryPolicy{InitialBackoff: time.Second, Multiplier: 0.5}, attempt: 3, want: time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.policy.Backoff(test.attempt); got != test.want {
+				t.Errorf("Backoff(%d) = %v, want %v", test.attempt, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	policy := sandwich.NewRetryPolicy(3, time.Second, 0)
+
+	tests := []struct {
+		name    string
+		policy  *sandwich.RetryPolicy
+		err     error
+		attempt int
+		want    bool
+	}{
+		{name: "nil policy", policy: nil, err: errTest, attempt: 1, want: false},
+		{name: "error", policy: policy, err: errTest, attempt: 1, want: true},
+		{name: "last attempt", policy: policy, err: errTest, attempt: 3, want: false},
+		{name: "panic", policy: policy, err: &sandwich.PanicError{}, attempt: 1, want: false},
+		{
+			name:    "classifier",
+			policy:  sandwich.NewRetryPolicy(3, time.Second, 0).SetClassifier(func(err error) bool { return !errors.Is(err, errTest) }),
+			err:     errTest,
+			attempt: 1,
+			want:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.policy.ShouldRetry(test.err, test.attempt); got != test.want {
+				t.Errorf("ShouldRetry(%v, %d) = %v, want %v", test.err, test.attempt, got, test.want)
+			}
+		})
+	}
+}
+
+// TestRetryOutlivesDispatchContext makes sure retries are not cancelled with the context
+// the event was dispatched with.
+func TestRetryOutlivesDispatchContext(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	retried := make(chan error, 1)
+
+	var attempts int
+
+	sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(eventCtx *sandwich.EventContext) error {
+		attempts++
+		if attempts == 1 {
+			cancel()
+
+			return errTest
+		}
+
+		retried <- eventCtx.Context.Err()
+
+		return nil
+	}).SetRetryPolicy(sandwich.NewRetryPolicy(2, time.Millisecond, 0))
+
+	err := harness.Sandwich.DispatchProducedPayload(ctx, sandwichtest.Resumed(discord.Resume{}))
+	if err != nil {
+		t.Fatalf("failed to dispatch: %v", err)
+	}
+
+	select {
+	case err := <-retried:
+		if err != nil {
+			t.Errorf("retry was called with a done context: %v", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("listener was not retried")
+	}
+}
+
+func TestRetryAbandonedOnClose(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	sink := &memoryDeadLetterSink{}
+	harness.Sandwich.SetDeadLetterSink(sink)
+
+	failed := make(chan struct{})
+
+	sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(*sandwich.EventContext) error {
+		close(failed)
+
+		return errTest
+	}).SetRetryPolicy(sandwich.NewRetryPolicy(2, time.Hour, 0))
+
+	err := harness.Dispatch(sandwichtest.Resumed(discord.Resume{}))
+	if err != nil {
+		t.Fatalf("failed to dispatch: %v", err)
+	}
+
+	<-failed
+
+	abandoned, err := harness.Bot.Handlers.Close(context.Background())
+	if err != nil || abandoned != 1 {
+		t.Fatalf("Close returned %d, %v, want 1, nil", abandoned, err)
+	}
+
+	reasons := sink.reasons()
+	if len(reasons) != 1 || reasons[0] != sandwich.DeadLetterRetryAbandoned {
+		t.Fatalf("dead letters have reasons %v, want [%s]", reasons, sandwich.DeadLetterRetryAbandoned)
+	}
+}
+
+func TestRetryRefusedOnClose(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	sink := &memoryDeadLetterSink{}
+	harness.Sandwich.SetDeadLetterSink(sink)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(*sandwich.EventContext) error {
+		close(started)
+		<-release
+
+		return errTest
+	}).SetRetryPolicy(sandwich.NewRetryPolicy(2, time.Millisecond, 0))
+
+	err := harness.Dispatch(sandwichtest.Resumed(discord.Resume{}))
+	if err != nil {
+		t.Fatalf("failed to dispatch: %v", err)
+	}
+
+	<-started
+
+	type closeResult struct {
+		abandoned int
+		err       error
+	}
+
+	closed := make(chan closeResult, 1)
+
+	go func() {
+		abandoned, err := harness.Bot.Handlers.Close(context.Background())
+		closed <- closeResult{abandoned: abandoned, err: err}
+	}()
+
+	// The listener fails once retries are no longer accepted, so its retry is refused.
+	for !harness.Bot.Handlers.RetriesStopped() {
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+
+	result := <-closed
+	if result.err != nil || result.abandoned != 1 {
+		t.Fatalf("Close returned %d, %v, want 1, nil", result.abandoned, result.err)
+	}
+
+	reasons := sink.reasons()
+	if len(reasons) != 1 || reasons[0] != sandwich.DeadLetterRetryAbandoned {
+		t.Fatalf("dead letters have reasons %v, want [%s]", reasons, sandwich.DeadLetterRetryAbandoned)
+	}
+}
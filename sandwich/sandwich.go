@@ -6,9 +6,10 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"os"
 	"os/signal"
-	"runtime/debug"
+	"slices"
 	"sync"
 	"syscall"
 	"time"
@@ -25,6 +26,9 @@ const VERSION = "1.1.0"
 
 var LastRequestTimeout = time.Minute * 60
 
+// PanicHookFunc is called with a panic recovered whilst dispatching an event.
+type PanicHookFunc func(eventCtx *EventContext, panicErr *PanicError)
+
 type Sandwich struct {
 	Logger *slog.Logger
 
@@ -44,6 +48,19 @@ type Sandwich struct {
 	SandwichClient sandwich_protobuf.SandwichClient
 
 	ErrorOnInvalidIdentifier bool
+
+	// DeadLetterSink receives events that could not be handled.
+	DeadLetterSink DeadLetterSink
+
+	// PanicHook is called with every panic recovered whilst dispatching events.
+	PanicHook PanicHookFunc
+
+	stopping     chan struct{}
+	stoppingOnce sync.Once
+
+	// abandoned is cancelled once Shutdown stops waiting for queued events to be handled.
+	abandoned context.Context
+	abandon   context.CancelFunc
 }
 
 func NewSandwich(conn grpc.ClientConnInterface, restInterface discord.RESTInterface, logger io.Writer) *Sandwich {
@@ -66,8 +83,13 @@ func NewSandwich(conn grpc.ClientConnInterface, restInterface discord.RESTInterf
 		SandwichClient: sandwich_protobuf.NewSandwichClient(conn),
 
 		ErrorOnInvalidIdentifier: false,
+
+		stopping:     make(chan struct{}),
+		stoppingOnce: sync.Once{},
 	}
 
+	sandwich.abandoned, sandwich.abandon = context.WithCancel(context.Background())
+
 	return sandwich
 }
 
@@ -75,120 +97,309 @@ func (sandwich *Sandwich) SetErrorOnInvalidIdentifier(value bool) {
 	sandwich.ErrorOnInvalidIdentifier = value
 }
 
-func (sandwich *Sandwich) ListenToChannel(ctx context.Context, channel chan []byte) error {
-	// Signal
-	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
+// ListenOption configures ListenToChannel and ListenToMessages.
+type ListenOption func(options *listenOptions)
 
-	// Register message channels
-	grpcMessages := make(chan *sandwich_protobuf.ListenResponse)
+type listenOptions struct {
+	recorder Recorder
 
-	go func() {
-		for {
-			grpcListener, err := sandwich.SandwichClient.Listen(ctx, &sandwich_protobuf.ListenRequest{
-				Identifier: "",
-			})
-			if err != nil {
-				sandwich.Logger.Warn("Failed to listen to grpc", "error", err)
+	reconnectBackoff    time.Duration
+	reconnectMaxBackoff time.Duration
+	idleTimeout         time.Duration
 
-				time.Sleep(time.Second)
-			} else {
-				for {
-					var listenResponse sandwich_protobuf.ListenResponse
+	grpcApplications []string
+	grpcEvents       map[string]bool
 
-					err = grpcListener.RecvMsg(&listenResponse)
-					if err != nil {
-						if errors.Is(err, context.Canceled) {
-							return
-						}
+	// routeGRPCEvents sends Discord events received from gRPC to the bots. Set by
+	// WithGRPCApplications and WithGRPCEvents.
+	routeGRPCEvents bool
 
-						sandwich.Logger.Warn("Failed to receive grpc message", "error", err)
+	maxInFlight int
+}
 
-						break
-					} else {
-						grpcMessages <- &listenResponse
-					}
-				}
+// ListenToChannel dispatches payloads received from the channel and the gRPC listener
+// until the context is done or Shutdown is called, in which case it returns nil.
+// It returns an error if the channel is closed or the gRPC listener fails in a way
+// that cannot be recovered from. Use SignalContext to stop on SIGINT or SIGTERM.
+func (sandwich *Sandwich) ListenToChannel(ctx context.Context, channel chan []byte, opts ...ListenOption) error {
+	return sandwich.listen(ctx, channel, nil, opts)
+}
+
+// ListenToMessages is similar to ListenToChannel however messages are acknowledged once
+// every listener of their event has finished, or negatively acknowledged if the parser
+// or a listener failed or the event was dropped, so they are delivered again.
+// Listeners run as they do for ListenToChannel, and messages are only settled once every
+// retry of their listeners has finished. Messages that cannot be unmarshalled are sent to the
+// dead-letter sink and acknowledged, as delivering them again would not help.
+// Use WithMaxInFlight to limit how many messages are handled at once.
+func (sandwich *Sandwich) ListenToMessages(ctx context.Context, messages <-chan *MQMessage, opts ...ListenOption) error {
+	return sandwich.listen(ctx, nil, messages, opts)
+}
+
+func (sandwich *Sandwich) listen(ctx context.Context, channel chan []byte, messages <-chan *MQMessage, opts []ListenOption) error {
+	options := &listenOptions{
+		reconnectBackoff:    defaultReconnectBackoff,
+		reconnectMaxBackoff: defaultReconnectMaxBackoff,
+		maxInFlight:         defaultMaxInFlight,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// Events are still handled once the event loop exits, so they can be drained by Shutdown.
+	dispatchCtx := sandwich.dispatchContext(ctx)
+
+	// Stops the gRPC listener once the event loop exits.
+	ctx, cancel := context.WithCancel(ctx)
+
+	subscriptions := options.grpcSubscriptions()
+
+	grpcMessages := make(chan grpcMessage)
+	listenErrors := make(chan error, len(subscriptions)+1)
+
+	var listenWg sync.WaitGroup
+
+	for _, subscription := range subscriptions {
+		listenWg.Go(func() {
+			err := sandwich.listenGRPC(ctx, dispatchCtx, options, subscription, grpcMessages)
+			if err != nil {
+				listenErrors <- err
 			}
-		}
+		})
+	}
+
+	if messages != nil {
+		listenWg.Go(func() {
+			err := sandwich.receiveMessages(ctx, dispatchCtx, options, messages)
+			if err != nil {
+				listenErrors <- err
+			}
+		})
+	}
+
+	defer func() {
+		cancel()
+		listenWg.Wait()
 	}()
 
 	// Event Loop
-eventLoop:
 	for {
 		select {
 		case grpcMessage := <-grpcMessages:
+			data := grpcMessage.response.GetData()
+
+			sandwich.recordMessage(options.recorder, data)
+
 			var payload sandwich_daemon.ProducedPayload
 
-			err := json.Unmarshal(grpcMessage.Data, &payload)
+			err := json.Unmarshal(data, &payload)
 			if err != nil {
 				sandwich.Logger.Warn("Failed to unmarshal grpc message", "error", err)
-			} else {
-				sandwich.DispatchGRPCPayload(ctx, payload)
+
+				sandwich.deadLetter(ctx, &DeadLetter{
+					Reason:   DeadLetterUnmarshalFailed,
+					Error:    err.Error(),
+					Raw:      data,
+					FailedAt: time.Now(),
+				})
+			} else if sandwich.acceptGRPCPayload(options, grpcMessage.subscription, &payload) {
+				err = sandwich.DispatchGRPCPayload(dispatchCtx, payload)
+				if err != nil {
+					sandwich.Logger.Warn("Failed to dispatch grpc payload", "error", err)
+				}
+			}
+		case stanMessage, ok := <-channel:
+			if !ok {
+				return ErrChannelClosed
 			}
-		case stanMessage := <-channel:
+
+			sandwich.recordMessage(options.recorder, stanMessage)
+
 			var payload sandwich_daemon.ProducedPayload
 
 			err := json.Unmarshal(stanMessage, &payload)
 			if err != nil {
 				sandwich.Logger.Warn("Failed to unmarshal stan message", "error", err)
+
+				sandwich.deadLetter(ctx, &DeadLetter{
+					Reason:   DeadLetterUnmarshalFailed,
+					Error:    err.Error(),
+					Raw:      stanMessage,
+					FailedAt: time.Now(),
+				})
 			} else {
-				err = sandwich.DispatchProducedPayload(ctx, payload)
+				err = sandwich.DispatchProducedPayload(dispatchCtx, payload)
 				if err != nil {
 					sandwich.Logger.Warn("Failed to dispatch sandwich payload", "error", err)
 				}
 			}
-		case <-signalCh:
-			break eventLoop
+		case err := <-listenErrors:
+			return err
+		case <-ctx.Done():
+			return nil
+		case <-sandwich.stopping:
+			return nil
 		}
 	}
+}
 
-	return nil
+// dispatchContext returns the context of events received by a listener. It is not cancelled
+// with the listener, so queued events are handled whilst Shutdown drains the queues, but is
+// cancelled if Shutdown stops waiting for them.
+func (sandwich *Sandwich) dispatchContext(ctx context.Context) context.Context {
+	dispatchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	context.AfterFunc(sandwich.abandoned, cancel)
+
+	return dispatchCtx
 }
 
-func (sandwich *Sandwich) DispatchGRPCPayload(ctx context.Context, payload sandwich_daemon.ProducedPayload) {
-	logger := sandwich.Logger.With("application", payload.Metadata.Application)
+// SignalContext returns a copy of the parent context that is cancelled when SIGINT or
+// SIGTERM is received, for passing to ListenToChannel. Call stop to stop listening for
+// signals once they are no longer needed.
+func SignalContext(parent context.Context) (ctx context.Context, stop context.CancelFunc) {
+	return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+}
 
-	sandwich.SandwichEvents.Dispatch(&EventContext{
-		Logger:   logger,
-		Sandwich: sandwich,
-		Session:  discord.NewSession("", sandwich.RESTInterface),
-		Handlers: sandwich.SandwichEvents,
-		Context:  ctx,
-		Payload:  &payload,
-	}, payload)
+// recordMessage passes a received message to the recorder, if there is one.
+func (sandwich *Sandwich) recordMessage(recorder Recorder, message []byte) {
+	if recorder == nil {
+		return
+	}
+
+	err := recorder.Record(message)
+	if err != nil {
+		sandwich.Logger.Warn("Failed to record message", "error", err)
+	}
+}
+
+// Shutdown stops ListenToChannel from receiving new payloads and waits for the events
+// already queued by every bot to be handled. Once the queues are drained, BotUnload is
+// called on every cog and Shutdown waits for the cogs to finish unloading.
+// If the context is done first, the events still queued are abandoned, the context of
+// events being handled is cancelled and the number of abandoned events is returned along
+// with the context error.
+func (sandwich *Sandwich) Shutdown(ctx context.Context) (abandoned int, err error) {
+	sandwich.stoppingOnce.Do(func() {
+		close(sandwich.stopping)
+	})
+
+	stopAbandon := context.AfterFunc(ctx, sandwich.abandon)
+	defer stopAbandon()
+
+	sandwich.botsMu.RLock()
+	bots := slices.Collect(maps.Values(sandwich.Bots))
+	sandwich.botsMu.RUnlock()
+
+	handlers := []*Handlers{sandwich.SandwichEvents}
+	for _, bot := range bots {
+		handlers = append(handlers, bot.Handlers)
+	}
+
+	var (
+		handlersWg  sync.WaitGroup
+		abandonedMu sync.Mutex
+	)
+
+	for _, handler := range handlers {
+		handlersWg.Go(func() {
+			handlerAbandoned, handlerErr := handler.Close(ctx)
+
+			abandonedMu.Lock()
+			abandoned += handlerAbandoned
+			abandonedMu.Unlock()
+
+			if handlerErr != nil {
+				sandwich.Logger.Warn("Abandoned queued events on shutdown", "abandoned", handlerAbandoned, "error", handlerErr)
+			}
+		})
+	}
+
+	handlersWg.Wait()
+
+	unloadWg := &sync.WaitGroup{}
+
+	for _, bot := range bots {
+		bot.UnloadCogs(unloadWg)
+	}
+
+	unloaded := make(chan struct{})
+
+	go func() {
+		unloadWg.Wait()
+		close(unloaded)
+	}()
+
+	select {
+	case <-unloaded:
+	case <-ctx.Done():
+		sandwich.Logger.Warn("Timed out waiting for cogs to unload", "error", ctx.Err())
+	}
+
+	return abandoned, ctx.Err()
+}
+
+// DispatchGRPCPayload dispatches a payload received from gRPC. Sandwich events are sent to
+// the SandwichEvents and every other event is sent to the bot of its identifier, the same
+// as DispatchProducedPayload.
+func (sandwich *Sandwich) DispatchGRPCPayload(ctx context.Context, payload sandwich_daemon.ProducedPayload) error {
+	if !sandwich.SandwichEvents.hasEventHandler(payload.Type) {
+		return sandwich.DispatchProducedPayload(ctx, payload)
+	}
+
+	sandwich.SandwichEvents.Dispatch(sandwich.newEventContext(ctx, sandwich.SandwichEvents, &payload), payload)
+
+	return nil
 }
 
 func (sandwich *Sandwich) DispatchProducedPayload(ctx context.Context, payload sandwich_daemon.ProducedPayload) error {
+	bot, err := sandwich.payloadBot(payload)
+	if bot == nil {
+		return err
+	}
+
+	bot.Dispatch(sandwich.newEventContext(ctx, bot.Handlers, &payload), payload)
+
+	return nil
+}
+
+// payloadBot returns the bot registered for the identifier of a payload. If there is
+// no bot, nil is returned along with ErrInvalidIdentifier if ErrorOnInvalidIdentifier is set.
+func (sandwich *Sandwich) payloadBot(payload sandwich_daemon.ProducedPayload) (*Bot, error) {
 	sandwich.botsMu.RLock()
 	bot, ok := sandwich.Bots[payload.Metadata.Identifier]
 	sandwich.botsMu.RUnlock()
 
 	if !ok {
 		if !sandwich.ErrorOnInvalidIdentifier {
-			return nil
+			return nil, nil
 		} else {
 			sandwich.Logger.Debug("Invalid identifier",
 				"identifier", payload.Metadata.Identifier,
 				"application", payload.Metadata.Application,
 				"error", ErrInvalidIdentifier)
 
-			return ErrInvalidIdentifier
+			return nil, ErrInvalidIdentifier
 		}
 	}
 
-	logger := sandwich.Logger.With("application", payload.Metadata.Application)
+	return bot, nil
+}
+
+func (sandwich *Sandwich) newEventContext(ctx context.Context, handlers *Handlers, payload *sandwich_daemon.ProducedPayload) *EventContext {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
-	bot.Dispatch(&EventContext{
-		Logger:   logger,
+	return &EventContext{
+		Logger:   sandwich.Logger.With("application", payload.Metadata.Application),
 		Sandwich: sandwich,
 		Session:  discord.NewSession("", sandwich.RESTInterface),
-		Handlers: bot.Handlers,
+		Handlers: handlers,
 		Context:  ctx,
-		Payload:  &payload,
-	}, payload)
+		Payload:  payload,
 
-	return nil
+		receivedAt: time.Now(),
+	}
 }
 
 func (sandwich *Sandwich) RegisterBot(identifier string, bot *Bot) {
@@ -197,13 +408,32 @@ func (sandwich *Sandwich) RegisterBot(identifier string, bot *Bot) {
 	sandwich.botsMu.Unlock()
 }
 
+// SetPanicHook sets a func called with every panic recovered whilst dispatching events,
+// such as to report them to an error tracker. Panics are still logged and passed to ERROR handlers.
+func (sandwich *Sandwich) SetPanicHook(hook PanicHookFunc) {
+	sandwich.PanicHook = hook
+}
+
 func (sandwich *Sandwich) RecoverEventPanic(errorValue any, eventCtx *EventContext, payload *sandwich_daemon.ProducedPayload) {
+	sandwich.handleEventPanic(eventCtx, payload, newPanicError(errorValue, ""))
+}
+
+// handleEventPanic logs a recovered panic and passes it to the PanicHook.
+func (sandwich *Sandwich) handleEventPanic(eventCtx *EventContext, payload *sandwich_daemon.ProducedPayload, panicErr *PanicError) {
+	var eventType string
+	if payload != nil {
+		eventType = payload.Type
+	}
+
 	sandwich.Logger.Error("Recovered panic on event dispatch",
-		"errorValue", errorValue,
-		"type", payload.Type)
+		"errorValue", panicErr.Value,
+		"type", eventType,
+		"handler", panicErr.Handler,
+		"stack", string(panicErr.Stack))
 
-	stackTrace := debug.Stack()
-	println(string(stackTrace))
+	if sandwich.PanicHook != nil {
+		sandwich.PanicHook(eventCtx, panicErr)
+	}
 }
 
 func (sandwich *Sandwich) FetchIdentifier(ctx context.Context, applicationName string) (identifier *sandwich_protobuf.SandwichApplication, ok bool, err error) {
@@ -272,6 +502,74 @@ type EventContext struct {
 	Guild *discord.Guild
 
 	Payload *sandwich_daemon.ProducedPayload
+
+	receivedAt time.Time
+
+	// dispatchErrors collects the errors of a synchronous or acknowledged dispatch.
+	dispatchErrors *dispatchErrors
+
+	// synchronous runs listeners and their retries on the current goroutine, one after another.
+	synchronous bool
+
+	// onDispatched is called with the result of an acknowledged dispatch once it has finished.
+	onDispatched func(result *DispatchResult)
+}
+
+// dispatchErrors collects the errors of every listener of a synchronous or acknowledged dispatch.
+type dispatchErrors struct {
+	mu     sync.Mutex
+	errors []*HandlerError
+
+	// pending counts the dispatch and the retries of its listeners that have not finished.
+	pending int
+}
+
+// collectError adds an error to the errors of a synchronous or acknowledged dispatch, if there is one.
+func (eventCtx *EventContext) collectError(handlerErr *HandlerError) {
+	if eventCtx.dispatchErrors == nil {
+		return
+	}
+
+	eventCtx.dispatchErrors.mu.Lock()
+	eventCtx.dispatchErrors.errors = append(eventCtx.dispatchErrors.errors, handlerErr)
+	eventCtx.dispatchErrors.mu.Unlock()
+}
+
+// dispatched passes the result of an acknowledged dispatch to onDispatched, if the event
+// belongs to one.
+func (eventCtx *EventContext) dispatched(result *DispatchResult) {
+	if eventCtx.onDispatched != nil {
+		eventCtx.onDispatched(result)
+	}
+}
+
+// retryPending marks a retry of a listener of an acknowledged dispatch as pending, so the
+// dispatch is not settled until settleDispatch is called for it.
+func (eventCtx *EventContext) retryPending() {
+	if eventCtx.onDispatched == nil {
+		return
+	}
+
+	eventCtx.dispatchErrors.mu.Lock()
+	eventCtx.dispatchErrors.pending++
+	eventCtx.dispatchErrors.mu.Unlock()
+}
+
+// settleDispatch marks an acknowledged dispatch, or a retry of one of its listeners, as
+// finished. Once nothing is pending, the result is passed to onDispatched.
+func (eventCtx *EventContext) settleDispatch() {
+	if eventCtx.onDispatched == nil {
+		return
+	}
+
+	eventCtx.dispatchErrors.mu.Lock()
+	eventCtx.dispatchErrors.pending--
+	settled := eventCtx.dispatchErrors.pending == 0
+	eventCtx.dispatchErrors.mu.Unlock()
+
+	if settled {
+		eventCtx.dispatched(eventCtx.dispatchResult(eventCtx.Payload.Type))
+	}
 }
 
 func (eventCtx *EventContext) ToGRPCContext() *GRPCContext {
@@ -0,0 +1,307 @@
+package internal_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+)
+
+// unloadCog finishes unloading in the background once unload is closed.
+type unloadCog struct {
+	unload   chan struct{}
+	unloaded atomic.Bool
+}
+
+func (cog *unloadCog) CogInfo() *sandwich.CogInfo {
+	return &sandwich.CogInfo{Name: "unload"}
+}
+
+func (cog *unloadCog) RegisterCog(*sandwich.Bot) error {
+	return nil
+}
+
+func (cog *unloadCog) BotUnload(_ *sandwich.Bot, wg *sync.WaitGroup) {
+	wg.Go(func() {
+		<-cog.unload
+		cog.unloaded.Store(true)
+	})
+}
+
+func TestShutdown(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	cog := &unloadCog{unload: make(chan struct{})}
+	bot.MustRegisterCog(cog)
+
+	var handled atomic.Int64
+
+	bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+		time.Sleep(time.Millisecond * 10)
+		handled.Add(1)
+
+		return nil
+	})
+
+	const payloads = 5
+
+	for range payloads {
+		dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+	}
+
+	close(cog.unload)
+
+	abandoned, err := sandwichClient.Shutdown(context.Background())
+	if abandoned != 0 || err != nil {
+		t.Fatalf("Shutdown returned %d, %v, want 0, nil", abandoned, err)
+	}
+
+	if got := handled.Load(); got != payloads {
+		t.Errorf("handled %d events, want %d", got, payloads)
+	}
+
+	if !cog.unloaded.Load() {
+		t.Error("Shutdown returned before the cog unloaded")
+	}
+}
+
+func TestShutdownTimesOutUnloadingCogs(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	cog := &unloadCog{unload: make(chan struct{})}
+	bot.MustRegisterCog(cog)
+
+	defer close(cog.unload)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	_, err := sandwichClient.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown returned %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestListenToChannelDrainsWithLiveContext(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	var (
+		mu        sync.Mutex
+		handled   int
+		cancelled int
+	)
+
+	sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(eventCtx *sandwich.EventContext) error {
+		time.Sleep(time.Millisecond * 10)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		handled++
+
+		if eventCtx.Context.Err() != nil {
+			cancelled++
+		}
+
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	channel := make(chan []byte)
+	listenErr := make(chan error, 1)
+
+	go func() {
+		listenErr <- harness.Sandwich.ListenToChannel(ctx, channel)
+	}()
+
+	const payloads = 10
+
+	for range payloads {
+		channel <- sandwichtest.Marshal(sandwichtest.Resumed(discord.Resume{}))
+	}
+
+	cancel()
+
+	err := <-listenErr
+	if err != nil {
+		t.Fatalf("ListenToChannel returned %v, want nil", err)
+	}
+
+	abandoned, err := harness.Sandwich.Shutdown(context.Background())
+	if err != nil || abandoned != 0 {
+		t.Fatalf("Shutdown returned %d, %v, want 0, nil", abandoned, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if handled != payloads {
+		t.Errorf("handled %d events, want %d", handled, payloads)
+	}
+
+	if cancelled != 0 {
+		t.Errorf("%d events were handled with a cancelled context, want 0", cancelled)
+	}
+}
+
+// listener starts a listener of a Sandwich and returns funcs to send it payloads and to
+// close the channel it receives from, along with a channel the listener returns to.
+type listener func(ctx context.Context, sw *sandwich.Sandwich) (send func(data []byte), closeChannel func(), listenErr <-chan error)
+
+func listenChannel(ctx context.Context, sw *sandwich.Sandwich) (func(data []byte), func(), <-chan error) {
+	channel := make(chan []byte)
+	listenErr := make(chan error, 1)
+
+	go func() {
+		listenErr <- sw.ListenToChannel(ctx, channel)
+	}()
+
+	return func(data []byte) { channel <- data }, func() { close(channel) }, listenErr
+}
+
+func listenMessages(ctx context.Context, sw *sandwich.Sandwich) (func(data []byte), func(), <-chan error) {
+	messages := make(chan *sandwich.MQMessage)
+	listenErr := make(chan error, 1)
+
+	go func() {
+		listenErr <- sw.ListenToMessages(ctx, messages)
+	}()
+
+	return func(data []byte) { messages <- &sandwich.MQMessage{Data: data} }, func() { close(messages) }, listenErr
+}
+
+func TestListenStops(t *testing.T) {
+	listeners := []struct {
+		name   string
+		listen listener
+	}{
+		{name: "ListenToChannel", listen: listenChannel},
+		{name: "ListenToMessages", listen: listenMessages},
+	}
+
+	tests := []struct {
+		name    string
+		stop    func(t *testing.T, harness *sandwichtest.Harness, cancel context.CancelFunc, closeChannel func())
+		wantErr error
+	}{
+		{
+			name: "cancelled",
+			stop: func(_ *testing.T, _ *sandwichtest.Harness, cancel context.CancelFunc, _ func()) {
+				cancel()
+			},
+			wantErr: nil,
+		},
+		{
+			name: "channel closed",
+			stop: func(_ *testing.T, _ *sandwichtest.Harness, _ context.CancelFunc, closeChannel func()) {
+				closeChannel()
+			},
+			wantErr: sandwich.ErrChannelClosed,
+		},
+		{
+			name: "shutdown",
+			stop: func(t *testing.T, harness *sandwichtest.Harness, _ context.CancelFunc, _ func()) {
+				abandoned, err := harness.Sandwich.Shutdown(context.Background())
+				if abandoned != 0 || err != nil {
+					t.Fatalf("Shutdown returned %d, %v, want 0, nil", abandoned, err)
+				}
+			},
+			wantErr: nil,
+		},
+	}
+
+	const payloads = 3
+
+	for _, listener := range listeners {
+		for _, test := range tests {
+			t.Run(listener.name+"/"+test.name, func(t *testing.T) {
+				harness := sandwichtest.New(t)
+
+				cog := &unloadCog{unload: make(chan struct{})}
+				harness.Bot.MustRegisterCog(cog)
+				close(cog.unload)
+
+				var handled atomic.Int64
+
+				sandwich.On(harness.Bot.Handlers, sandwich.EventResumed, func(*sandwich.EventContext) error {
+					time.Sleep(time.Millisecond * 10)
+					handled.Add(1)
+
+					return nil
+				})
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				send, closeChannel, listenErr := listener.listen(ctx, harness.Sandwich)
+
+				for range payloads {
+					send(sandwichtest.Marshal(sandwichtest.Resumed(discord.Resume{})))
+				}
+
+				test.stop(t, harness, cancel, closeChannel)
+
+				select {
+				case err := <-listenErr:
+					if !errors.Is(err, test.wantErr) {
+						t.Errorf("%s returned %v, want %v", listener.name, err, test.wantErr)
+					}
+				case <-time.After(time.Second * 5):
+					t.Fatalf("%s did not return", listener.name)
+				}
+
+				// Events received before the listener stopped are still handled.
+				harness.Close()
+
+				if got := handled.Load(); got != payloads {
+					t.Errorf("handled %d events, want %d", got, payloads)
+				}
+
+				if !cog.unloaded.Load() {
+					t.Error("cog was not unloaded")
+				}
+			})
+		}
+	}
+}
+
+func TestSignalContext(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	ctx, stop := sandwich.SignalContext(context.Background())
+	defer stop()
+
+	_, _, listenErr := listenChannel(ctx, harness.Sandwich)
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("failed to find process: %v", err)
+	}
+
+	// Not every platform can send signals to a process.
+	err = process.Signal(syscall.SIGTERM)
+	if err != nil {
+		t.Skipf("failed to send signal: %v", err)
+	}
+
+	select {
+	case err := <-listenErr:
+		if err != nil {
+			t.Errorf("ListenToChannel returned %v, want nil", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("ListenToChannel did not return once the signal was received")
+	}
+
+	if cause := context.Cause(ctx); cause == nil {
+		t.Error("context was not cancelled by the signal")
+	}
+}
@@ -0,0 +1,690 @@
+package sandwichtest
+
+import (
+	"context"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich_protobuf "github.com/WelcomerTeam/Sandwich-Daemon/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Call is a request made to the Client.
+type Call struct {
+	Method  string
+	Request proto.Message
+}
+
+// Client is an in-memory sandwich_protobuf.SandwichClient. Its state is seeded with the Add
+// methods and returned by the Fetch methods. Fetch requests without IDs return every seeded
+// value of the guild. Payloads passed to Send are received by every open Listen stream.
+type Client struct {
+	mu sync.RWMutex
+
+	applications map[string]*sandwich_protobuf.SandwichApplication
+	guilds       map[int64]*sandwich_protobuf.Guild
+	channels     map[int64]map[int64]*sandwich_protobuf.Channel
+	roles        map[int64]map[int64]*sandwich_protobuf.Role
+	emojis       map[int64]map[int64]*sandwich_protobuf.Emoji
+	stickers     map[int64]map[int64]*sandwich_protobuf.Sticker
+	members      map[int64]map[int64]*sandwich_protobuf.GuildMember
+	voiceStates  map[int64]map[int64]*sandwich_protobuf.VoiceState
+	users        map[int64]*sandwich_protobuf.User
+	locations    map[int64]map[int64]*sandwich_protobuf.WhereIsGuildLocation
+
+	errors map[string]error
+	calls  []Call
+
+	listeners map[*listenStream]struct{}
+}
+
+var _ sandwich_protobuf.SandwichClient = (*Client)(nil)
+
+func NewClient() *Client {
+	return &Client{
+		mu: sync.RWMutex{},
+
+		applications: make(map[string]*sandwich_protobuf.SandwichApplication),
+		guilds:       make(map[int64]*sandwich_protobuf.Guild),
+		channels:     make(map[int64]map[int64]*sandwich_protobuf.Channel),
+		roles:        make(map[int64]map[int64]*sandwich_protobuf.Role),
+		emojis:       make(map[int64]map[int64]*sandwich_protobuf.Emoji),
+		stickers:     make(map[int64]map[int64]*sandwich_protobuf.Sticker),
+		members:      make(map[int64]map[int64]*sandwich_protobuf.GuildMember),
+		voiceStates:  make(map[int64]map[int64]*sandwich_protobuf.VoiceState),
+		users:        make(map[int64]*sandwich_protobuf.User),
+		locations:    make(map[int64]map[int64]*sandwich_protobuf.WhereIsGuildLocation),
+
+		errors: make(map[string]error),
+		calls:  make([]Call, 0),
+
+		listeners: make(map[*listenStream]struct{}),
+	}
+}
+
+// AddApplication adds an application, returned by FetchApplication.
+func (c *Client) AddApplication(application *sandwich_protobuf.SandwichApplication) {
+	c.mu.Lock()
+	c.applications[application.GetApplicationIdentifier()] = application
+	c.mu.Unlock()
+}
+
+// AddGuild adds a guild along with its channels, roles, emojis, stickers, members and voice states.
+func (c *Client) AddGuild(guild discord.Guild) {
+	for _, channel := range guild.Channels {
+		c.AddChannel(guild.ID, channel)
+	}
+
+	for _, role := range guild.Roles {
+		c.AddRole(guild.ID, role)
+	}
+
+	for _, emoji := range guild.Emojis {
+		c.AddEmoji(guild.ID, emoji)
+	}
+
+	for _, sticker := range guild.Stickers {
+		c.AddSticker(guild.ID, sticker)
+	}
+
+	for _, member := range guild.Members {
+		c.AddGuildMember(guild.ID, member)
+	}
+
+	for _, voiceState := range guild.VoiceStates {
+		c.AddVoiceState(guild.ID, voiceState)
+	}
+
+	c.mu.Lock()
+	c.guilds[int64(guild.ID)] = sandwich_protobuf.GuildToPB(&guild)
+	c.mu.Unlock()
+}
+
+func (c *Client) AddChannel(guildID discord.Snowflake, channel discord.Channel) {
+	channel.GuildID = &guildID
+
+	c.mu.Lock()
+	addToGuild(c.channels, guildID, int64(channel.ID), sandwich_protobuf.ChannelToPB(&channel))
+	c.mu.Unlock()
+}
+
+func (c *Client) AddRole(guildID discord.Snowflake, role discord.Role) {
+	role.GuildID = &guildID
+
+	c.mu.Lock()
+	addToGuild(c.roles, guildID, int64(role.ID), sandwich_protobuf.RoleToPB(&role))
+	c.mu.Unlock()
+}
+
+func (c *Client) AddEmoji(guildID discord.Snowflake, emoji discord.Emoji) {
+	emoji.GuildID = &guildID
+
+	c.mu.Lock()
+	addToGuild(c.emojis, guildID, int64(emoji.ID), sandwich_protobuf.EmojiToPB(&emoji))
+	c.mu.Unlock()
+}
+
+func (c *Client) AddSticker(guildID discord.Snowflake, sticker discord.Sticker) {
+	sticker.GuildID = &guildID
+
+	c.mu.Lock()
+	addToGuild(c.stickers, guildID, int64(sticker.ID), sandwich_protobuf.StickerToPB(&sticker))
+	c.mu.Unlock()
+}
+
+// AddGuildMember adds a member to a guild. The user of the member is also added.
+func (c *Client) AddGuildMember(guildID discord.Snowflake, member discord.GuildMember) {
+	if member.User == nil {
+		return
+	}
+
+	member.GuildID = &guildID
+
+	c.AddUser(*member.User)
+
+	c.mu.Lock()
+	addToGuild(c.members, guildID, int64(member.User.ID), sandwich_protobuf.GuildMemberToPB(&member))
+	c.mu.Unlock()
+}
+
+func (c *Client) AddVoiceState(guildID discord.Snowflake, voiceState discord.VoiceState) {
+	voiceState.GuildID = &guildID
+
+	c.mu.Lock()
+	addToGuild(c.voiceStates, guildID, int64(voiceState.UserID), sandwich_protobuf.VoiceStateToPB(&voiceState))
+	c.mu.Unlock()
+}
+
+func (c *Client) AddUser(user discord.User) {
+	c.mu.Lock()
+	c.users[int64(user.ID)] = sandwich_protobuf.UserToPB(&user)
+	c.mu.Unlock()
+}
+
+// AddGuildLocation adds where a guild is, returned by WhereIsGuild.
+func (c *Client) AddGuildLocation(guildID discord.Snowflake, location *sandwich_protobuf.WhereIsGuildLocation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	locations := c.locations[int64(guildID)]
+	if locations == nil {
+		locations = make(map[int64]*sandwich_protobuf.WhereIsGuildLocation)
+		c.locations[int64(guildID)] = locations
+	}
+
+	locations[int64(len(locations))] = location
+}
+
+// SetError makes every call to a method, such as "FetchGuildMember", return err.
+// A nil error removes it.
+func (c *Client) SetError(method string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err == nil {
+		delete(c.errors, method)
+	} else {
+		c.errors[method] = err
+	}
+}
+
+// Calls returns every request made to the client, in the order they were made.
+func (c *Client) Calls() []Call {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.calls)
+}
+
+// CallsTo returns every request made to a method, in the order they were made.
+func (c *Client) CallsTo(method string) []proto.Message {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	requests := make([]proto.Message, 0)
+
+	for _, call := range c.calls {
+		if call.Method == method {
+			requests = append(requests, call.Request)
+		}
+	}
+
+	return requests
+}
+
+// Send sends a payload to every open Listen stream.
+func (c *Client) Send(payload sandwich_daemon.ProducedPayload) error {
+	data, err := marshalPayload(payload)
+	if err != nil {
+		return err
+	}
+
+	c.mu.RLock()
+	listeners := slices.Collect(maps.Keys(c.listeners))
+	c.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener.send(&sandwich_protobuf.ListenResponse{
+			Timestamp: 0,
+			Data:      data,
+		})
+	}
+
+	return nil
+}
+
+// CloseListeners ends every open Listen stream, which then fails with err, as if the
+// connection to sandwich was lost.
+func (c *Client) CloseListeners(err error) {
+	c.mu.Lock()
+	listeners := slices.Collect(maps.Keys(c.listeners))
+	clear(c.listeners)
+	c.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener.close(err)
+	}
+}
+
+// call records a request and returns the error set for the method, if any.
+func (c *Client) call(method string, request proto.Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls = append(c.calls, Call{Method: method, Request: request})
+
+	return c.errors[method]
+}
+
+func (c *Client) Listen(ctx context.Context, in *sandwich_protobuf.ListenRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[sandwich_protobuf.ListenResponse], error) {
+	if err := c.call("Listen", in); err != nil {
+		return nil, err
+	}
+
+	stream := &listenStream{
+		ctx:       ctx,
+		client:    c,
+		responses: make(chan *sandwich_protobuf.ListenResponse, 64),
+		closed:    make(chan struct{}),
+	}
+
+	c.mu.Lock()
+	c.listeners[stream] = struct{}{}
+	c.mu.Unlock()
+
+	return stream, nil
+}
+
+func (c *Client) RelayMessage(_ context.Context, in *sandwich_protobuf.RelayMessageRequest, _ ...grpc.CallOption) (*sandwich_protobuf.BaseResponse, error) {
+	if err := c.call("RelayMessage", in); err != nil {
+		return nil, err
+	}
+
+	var payload sandwich_daemon.ProducedPayload
+
+	payload.Op = discord.GatewayOpDispatch
+	payload.Type = in.GetType()
+	payload.Data = in.GetData()
+	payload.Metadata.Identifier = in.GetIdentifier()
+
+	if err := c.Send(payload); err != nil {
+		return &sandwich_protobuf.BaseResponse{Ok: false, Error: err.Error()}, nil
+	}
+
+	return okResponse(), nil
+}
+
+func (c *Client) ReloadConfiguration(_ context.Context, in *emptypb.Empty, _ ...grpc.CallOption) (*sandwich_protobuf.BaseResponse, error) {
+	if err := c.call("ReloadConfiguration", in); err != nil {
+		return nil, err
+	}
+
+	return okResponse(), nil
+}
+
+func (c *Client) FetchApplication(_ context.Context, in *sandwich_protobuf.ApplicationIdentifier, _ ...grpc.CallOption) (*sandwich_protobuf.FetchApplicationResponse, error) {
+	if err := c.call("FetchApplication", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	applications := make(map[string]*sandwich_protobuf.SandwichApplication)
+
+	for identifier, application := range c.applications {
+		if in.GetApplicationIdentifier() == "" || in.GetApplicationIdentifier() == identifier {
+			applications[identifier] = application
+		}
+	}
+
+	return &sandwich_protobuf.FetchApplicationResponse{
+		BaseResponse: okResponse(),
+		Applications: applications,
+	}, nil
+}
+
+func (c *Client) StartApplication(_ context.Context, in *sandwich_protobuf.ApplicationIdentifierWithBlocking, _ ...grpc.CallOption) (*sandwich_protobuf.BaseResponse, error) {
+	if err := c.call("StartApplication", in); err != nil {
+		return nil, err
+	}
+
+	return c.applicationResponse(in.GetApplicationIdentifier()), nil
+}
+
+func (c *Client) StopApplication(_ context.Context, in *sandwich_protobuf.ApplicationIdentifierWithBlocking, _ ...grpc.CallOption) (*sandwich_protobuf.BaseResponse, error) {
+	if err := c.call("StopApplication", in); err != nil {
+		return nil, err
+	}
+
+	return c.applicationResponse(in.GetApplicationIdentifier()), nil
+}
+
+func (c *Client) CreateApplication(_ context.Context, in *sandwich_protobuf.CreateApplicationRequest, _ ...grpc.CallOption) (*sandwich_protobuf.SandwichApplication, error) {
+	if err := c.call("CreateApplication", in); err != nil {
+		return nil, err
+	}
+
+	application := &sandwich_protobuf.SandwichApplication{
+		ApplicationIdentifier: in.GetApplicationIdentifier(),
+		ProducerIdentifier:    in.GetProducerIdentifier(),
+		DisplayName:           in.GetDisplayName(),
+		BotToken:              in.GetBotToken(),
+	}
+
+	c.AddApplication(application)
+
+	return application, nil
+}
+
+func (c *Client) DeleteApplication(_ context.Context, in *sandwich_protobuf.ApplicationIdentifier, _ ...grpc.CallOption) (*sandwich_protobuf.BaseResponse, error) {
+	if err := c.call("DeleteApplication", in); err != nil {
+		return nil, err
+	}
+
+	response := c.applicationResponse(in.GetApplicationIdentifier())
+
+	c.mu.Lock()
+	delete(c.applications, in.GetApplicationIdentifier())
+	c.mu.Unlock()
+
+	return response, nil
+}
+
+func (c *Client) RequestGuildChunk(_ context.Context, in *sandwich_protobuf.RequestGuildChunkRequest, _ ...grpc.CallOption) (*sandwich_protobuf.BaseResponse, error) {
+	if err := c.call("RequestGuildChunk", in); err != nil {
+		return nil, err
+	}
+
+	return okResponse(), nil
+}
+
+func (c *Client) SendWebsocketMessage(_ context.Context, in *sandwich_protobuf.SendWebsocketMessageRequest, _ ...grpc.CallOption) (*sandwich_protobuf.BaseResponse, error) {
+	if err := c.call("SendWebsocketMessage", in); err != nil {
+		return nil, err
+	}
+
+	return okResponse(), nil
+}
+
+func (c *Client) WhereIsGuild(_ context.Context, in *sandwich_protobuf.WhereIsGuildRequest, _ ...grpc.CallOption) (*sandwich_protobuf.WhereIsGuildResponse, error) {
+	if err := c.call("WhereIsGuild", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &sandwich_protobuf.WhereIsGuildResponse{
+		BaseResponse: okResponse(),
+		Locations:    maps.Clone(c.locations[in.GetGuildId()]),
+	}, nil
+}
+
+func (c *Client) FetchAllGuildIDs(_ context.Context, in *sandwich_protobuf.FetchGuildIDsRequest, _ ...grpc.CallOption) (*sandwich_protobuf.FetchGuildIDsResponse, error) {
+	if err := c.call("FetchAllGuildIDs", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &sandwich_protobuf.FetchGuildIDsResponse{
+		BaseResponse: okResponse(),
+		GuildIds:     slices.Sorted(maps.Keys(c.guilds)),
+	}, nil
+}
+
+func (c *Client) FetchGuild(_ context.Context, in *sandwich_protobuf.FetchGuildRequest, _ ...grpc.CallOption) (*sandwich_protobuf.FetchGuildResponse, error) {
+	if err := c.call("FetchGuild", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &sandwich_protobuf.FetchGuildResponse{
+		BaseResponse: okResponse(),
+		Guilds:       filterByID(c.guilds, in.GetGuildIds()),
+	}, nil
+}
+
+func (c *Client) FetchGuildMember(_ context.Context, in *sandwich_protobuf.FetchGuildMemberRequest, _ ...grpc.CallOption) (*sandwich_protobuf.FetchGuildMemberResponse, error) {
+	if err := c.call("FetchGuildMember", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &sandwich_protobuf.FetchGuildMemberResponse{
+		BaseResponse: okResponse(),
+		GuildMembers: filterByID(c.members[in.GetGuildId()], in.GetUserIds()),
+	}, nil
+}
+
+func (c *Client) FetchGuildChannel(_ context.Context, in *sandwich_protobuf.FetchGuildChannelRequest, _ ...grpc.CallOption) (*sandwich_protobuf.FetchGuildChannelResponse, error) {
+	if err := c.call("FetchGuildChannel", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &sandwich_protobuf.FetchGuildChannelResponse{
+		BaseResponse: okResponse(),
+		Channels:     filterByID(c.channels[in.GetGuildId()], in.GetChannelIds()),
+	}, nil
+}
+
+func (c *Client) FetchGuildRole(_ context.Context, in *sandwich_protobuf.FetchGuildRoleRequest, _ ...grpc.CallOption) (*sandwich_protobuf.FetchGuildRoleResponse, error) {
+	if err := c.call("FetchGuildRole", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &sandwich_protobuf.FetchGuildRoleResponse{
+		BaseResponse: okResponse(),
+		Roles:        filterByID(c.roles[in.GetGuildId()], in.GetRoleIds()),
+	}, nil
+}
+
+func (c *Client) FetchGuildEmoji(_ context.Context, in *sandwich_protobuf.FetchGuildEmojiRequest, _ ...grpc.CallOption) (*sandwich_protobuf.FetchGuildEmojiResponse, error) {
+	if err := c.call("FetchGuildEmoji", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &sandwich_protobuf.FetchGuildEmojiResponse{
+		BaseResponse: okResponse(),
+		Emojis:       filterByID(c.emojis[in.GetGuildId()], in.GetEmojiIds()),
+	}, nil
+}
+
+func (c *Client) FetchGuildSticker(_ context.Context, in *sandwich_protobuf.FetchGuildStickerRequest, _ ...grpc.CallOption) (*sandwich_protobuf.FetchGuildStickerResponse, error) {
+	if err := c.call("FetchGuildSticker", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &sandwich_protobuf.FetchGuildStickerResponse{
+		BaseResponse: okResponse(),
+		Stickers:     filterByID(c.stickers[in.GetGuildId()], in.GetStickerIds()),
+	}, nil
+}
+
+func (c *Client) FetchGuildVoiceState(_ context.Context, in *sandwich_protobuf.FetchGuildVoiceStateRequest, _ ...grpc.CallOption) (*sandwich_protobuf.FetchGuildVoiceStateResponse, error) {
+	if err := c.call("FetchGuildVoiceState", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &sandwich_protobuf.FetchGuildVoiceStateResponse{
+		BaseResponse: okResponse(),
+		VoiceStates:  filterByID(c.voiceStates[in.GetGuildId()], in.GetUserIds()),
+	}, nil
+}
+
+func (c *Client) FetchUser(_ context.Context, in *sandwich_protobuf.FetchUserRequest, _ ...grpc.CallOption) (*sandwich_protobuf.FetchUserResponse, error) {
+	if err := c.call("FetchUser", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &sandwich_protobuf.FetchUserResponse{
+		BaseResponse: okResponse(),
+		Users:        filterByID(c.users, in.GetUserIds()),
+	}, nil
+}
+
+func (c *Client) FetchUserMutualGuilds(_ context.Context, in *sandwich_protobuf.FetchUserMutualGuildsRequest, _ ...grpc.CallOption) (*sandwich_protobuf.FetchUserMutualGuildsResponse, error) {
+	if err := c.call("FetchUserMutualGuilds", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	guilds := make(map[int64]*sandwich_protobuf.Guild)
+
+	for guildID, members := range c.members {
+		if _, ok := members[in.GetUserId()]; !ok {
+			continue
+		}
+
+		if guild, ok := c.guilds[guildID]; ok {
+			guilds[guildID] = guild
+		}
+	}
+
+	return &sandwich_protobuf.FetchUserMutualGuildsResponse{
+		BaseResponse: okResponse(),
+		Guilds:       guilds,
+	}, nil
+}
+
+func (c *Client) FetchVoiceStates(_ context.Context, in *sandwich_protobuf.FetchVoiceStatesRequest, _ ...grpc.CallOption) (*sandwich_protobuf.FetchVoiceStatesResponse, error) {
+	if err := c.call("FetchVoiceStates", in); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	voiceStates := make(map[int64]*sandwich_protobuf.VoiceState)
+
+	for guildID, guildVoiceStates := range c.voiceStates {
+		if len(in.GetGuildIds()) == 0 || slices.Contains(in.GetGuildIds(), guildID) {
+			maps.Copy(voiceStates, guildVoiceStates)
+		}
+	}
+
+	return &sandwich_protobuf.FetchVoiceStatesResponse{
+		BaseResponse: okResponse(),
+		VoiceStates:  voiceStates,
+	}, nil
+}
+
+func (c *Client) applicationResponse(identifier string) *sandwich_protobuf.BaseResponse {
+	c.mu.RLock()
+	_, ok := c.applications[identifier]
+	c.mu.RUnlock()
+
+	if !ok {
+		return &sandwich_protobuf.BaseResponse{Ok: false, Error: "application not found"}
+	}
+
+	return okResponse()
+}
+
+func okResponse() *sandwich_protobuf.BaseResponse {
+	return &sandwich_protobuf.BaseResponse{Ok: true}
+}
+
+func addToGuild[T any](values map[int64]map[int64]T, guildID discord.Snowflake, id int64, value T) {
+	guildValues := values[int64(guildID)]
+	if guildValues == nil {
+		guildValues = make(map[int64]T)
+		values[int64(guildID)] = guildValues
+	}
+
+	guildValues[id] = value
+}
+
+// filterByID returns the values with the given IDs, or every value if no IDs are given.
+func filterByID[T any](values map[int64]T, ids []int64) map[int64]T {
+	if len(ids) == 0 {
+		return maps.Clone(values)
+	}
+
+	filtered := make(map[int64]T)
+
+	for _, id := range ids {
+		if value, ok := values[id]; ok {
+			filtered[id] = value
+		}
+	}
+
+	return filtered
+}
+
+// listenStream is a Listen stream receiving the payloads sent to its Client.
+type listenStream struct {
+	grpc.ClientStream
+
+	ctx       context.Context
+	client    *Client
+	responses chan *sandwich_protobuf.ListenResponse
+
+	// closed is closed by close, after which Recv returns err.
+	closed chan struct{}
+	err    error
+}
+
+func (s *listenStream) send(response *sandwich_protobuf.ListenResponse) {
+	select {
+	case s.responses <- response:
+	case <-s.closed:
+	case <-s.ctx.Done():
+	}
+}
+
+func (s *listenStream) close(err error) {
+	s.err = err
+	close(s.closed)
+}
+
+func (s *listenStream) Recv() (*sandwich_protobuf.ListenResponse, error) {
+	select {
+	case response := <-s.responses:
+		return response, nil
+	case <-s.closed:
+		return nil, s.err
+	case <-s.ctx.Done():
+		s.client.mu.Lock()
+		delete(s.client.listeners, s)
+		s.client.mu.Unlock()
+
+		return nil, s.ctx.Err()
+	}
+}
+
+func (s *listenStream) RecvMsg(m any) error {
+	response, err := s.Recv()
+	if err != nil {
+		return err
+	}
+
+	message, ok := m.(proto.Message)
+	if !ok {
+		return io.ErrUnexpectedEOF
+	}
+
+	proto.Reset(message)
+	proto.Merge(message, response)
+
+	return nil
+}
+
+func (s *listenStream) Header() (metadata.MD, error) { return metadata.MD{}, nil }
+func (s *listenStream) Trailer() metadata.MD         { return metadata.MD{} }
+func (s *listenStream) CloseSend() error             { return nil }
+func (s *listenStream) Context() context.Context     { return s.ctx }
+func (s *listenStream) SendMsg(any) error            { return nil }
@@ -0,0 +1,242 @@
+package sandwichtest_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	discord "github.com/WelcomerTeam/Discord/discord"
+	sandwich_protobuf "github.com/WelcomerTeam/Sandwich-Daemon/proto"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+)
+
+var errTest = errors.New("test error")
+
+const (
+	guildID   = discord.Snowflake(10)
+	channelID = discord.Snowflake(20)
+	userID    = discord.Snowflake(30)
+)
+
+// newSeededClient creates a Client with a guild, two channels and a member.
+func newSeededClient() *sandwichtest.Client {
+	client := sandwichtest.NewClient()
+
+	client.AddGuild(discord.Guild{ID: guildID, Name: "guild"})
+	client.AddChannel(guildID, discord.Channel{ID: channelID, Name: "general"})
+	client.AddChannel(guildID, discord.Channel{ID: channelID + 1, Name: "random"})
+	client.AddGuildMember(guildID, discord.GuildMember{User: &discord.User{ID: userID, Username: "user"}})
+
+	return client
+}
+
+func TestClientFetchGuild(t *testing.T) {
+	client := newSeededClient()
+
+	tests := []struct {
+		name     string
+		guildIDs []int64
+		want     []int64
+	}{
+		{name: "by id", guildIDs: []int64{int64(guildID)}, want: []int64{int64(guildID)}},
+		{name: "without ids", guildIDs: nil, want: []int64{int64(guildID)}},
+		{name: "unknown id", guildIDs: []int64{1}, want: []int64{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := client.FetchGuild(t.Context(), &sandwich_protobuf.FetchGuildRequest{GuildIds: test.guildIDs})
+			if err != nil {
+				t.Fatalf("failed to fetch guild: %v", err)
+			}
+
+			got := slices.Sorted(maps.Keys(response.GetGuilds()))
+			if !slices.Equal(got, test.want) {
+				t.Fatalf("returned guilds %v, want %v", got, test.want)
+			}
+
+			for _, guild := range response.GetGuilds() {
+				if guild.GetName() != "guild" {
+					t.Errorf("returned guild named %q, want %q", guild.GetName(), "guild")
+				}
+			}
+		})
+	}
+}
+
+func TestClientFetchGuildChannel(t *testing.T) {
+	client := newSeededClient()
+
+	tests := []struct {
+		name       string
+		guildID    discord.Snowflake
+		channelIDs []int64
+		want       []int64
+	}{
+		{name: "by id", guildID: guildID, channelIDs: []int64{int64(channelID)}, want: []int64{int64(channelID)}},
+		{name: "without ids", guildID: guildID, channelIDs: nil, want: []int64{int64(channelID), int64(channelID + 1)}},
+		{name: "unknown id", guildID: guildID, channelIDs: []int64{1}, want: []int64{}},
+		{name: "unknown guild", guildID: 1, channelIDs: nil, want: []int64{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := client.FetchGuildChannel(t.Context(), &sandwich_protobuf.FetchGuildChannelRequest{
+				GuildId:    int64(test.guildID),
+				ChannelIds: test.channelIDs,
+			})
+			if err != nil {
+				t.Fatalf("failed to fetch channels: %v", err)
+			}
+
+			got := slices.Sorted(maps.Keys(response.GetChannels()))
+			if !slices.Equal(got, test.want) {
+				t.Errorf("returned channels %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestClientFetchGuildMember(t *testing.T) {
+	client := newSeededClient()
+
+	tests := []struct {
+		name    string
+		userIDs []int64
+		want    []int64
+	}{
+		{name: "by id", userIDs: []int64{int64(userID)}, want: []int64{int64(userID)}},
+		{name: "without ids", userIDs: nil, want: []int64{int64(userID)}},
+		{name: "unknown id", userIDs: []int64{1}, want: []int64{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := client.FetchGuildMember(t.Context(), &sandwich_protobuf.FetchGuildMemberRequest{
+				GuildId: int64(guildID),
+				UserIds: test.userIDs,
+			})
+			if err != nil {
+				t.Fatalf("failed to fetch members: %v", err)
+			}
+
+			got := slices.Sorted(maps.Keys(response.GetGuildMembers()))
+			if !slices.Equal(got, test.want) {
+				t.Fatalf("returned members %v, want %v", got, test.want)
+			}
+
+			for _, member := range response.GetGuildMembers() {
+				if member.GetUser().GetUsername() != "user" {
+					t.Errorf("returned member named %q, want %q", member.GetUser().GetUsername(), "user")
+				}
+			}
+		})
+	}
+}
+
+func TestClientSetError(t *testing.T) {
+	client := newSeededClient()
+	client.SetError("FetchGuild", errTest)
+
+	request := &sandwich_protobuf.FetchGuildRequest{GuildIds: []int64{int64(guildID)}}
+
+	_, err := client.FetchGuild(t.Context(), request)
+	if !errors.Is(err, errTest) {
+		t.Fatalf("FetchGuild returned %v, want %v", err, errTest)
+	}
+
+	client.SetError("FetchGuild", nil)
+
+	_, err = client.FetchGuild(t.Context(), request)
+	if err != nil {
+		t.Fatalf("FetchGuild returned %v after the error was removed", err)
+	}
+
+	calls := client.CallsTo("FetchGuild")
+	if len(calls) != 2 {
+		t.Fatalf("recorded %d calls to FetchGuild, want 2", len(calls))
+	}
+
+	if calls[0] != request {
+		t.Errorf("recorded a different request")
+	}
+
+	if len(client.CallsTo("FetchGuildMember")) != 0 {
+		t.Errorf("recorded calls to a method that was not called")
+	}
+}
+
+func TestClientListen(t *testing.T) {
+	client := sandwichtest.NewClient()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	stream, err := client.Listen(ctx, &sandwich_protobuf.ListenRequest{})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	err = client.Send(sandwichtest.Resumed(discord.Resume{}))
+	if err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	response, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+
+	if len(response.GetData()) == 0 {
+		t.Errorf("received an empty response")
+	}
+
+	received := make(chan error, 1)
+
+	go func() {
+		_, err := stream.Recv()
+		received <- err
+	}()
+
+	cancel()
+
+	select {
+	case err := <-received:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Recv returned %v after the context was cancelled, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Recv did not return after the context was cancelled")
+	}
+
+	// Payloads sent once the stream is closed are not received by it.
+	err = client.Send(sandwichtest.Resumed(discord.Resume{}))
+	if err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+}
+
+func TestClientCloseListeners(t *testing.T) {
+	client := sandwichtest.NewClient()
+
+	stream, err := client.Listen(t.Context(), &sandwich_protobuf.ListenRequest{})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	client.CloseListeners(errTest)
+
+	_, err = stream.Recv()
+	if !errors.Is(err, errTest) {
+		t.Errorf("Recv returned %v after the stream was closed, want %v", err, errTest)
+	}
+
+	// Payloads sent once the stream is closed are not received by it.
+	err = client.Send(sandwichtest.Resumed(discord.Resume{}))
+	if err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package sandwichtest provides fakes for testing cogs and handlers without a running
+// sandwich daemon or discord.
+package sandwichtest
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich_protobuf "github.com/WelcomerTeam/Sandwich-Daemon/proto"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+// ShutdownTimeout is how long Close waits for queued events to be handled.
+var ShutdownTimeout = time.Second * 10
+
+// Harness is a Sandwich using an in-memory Client and a recording REST interface, with a
+// Bot registered under DefaultIdentifier.
+type Harness struct {
+	tb testing.TB
+
+	Client   *Client
+	REST     *REST
+	Sandwich *sandwich.Sandwich
+	Bot      *sandwich.Bot
+
+	closeOnce sync.Once
+}
+
+// New creates a Harness. DefaultApplication is seeded in the Client so payloads created
+// with the payload builders are dispatched to the Bot. The Harness is closed when the test ends.
+func New(tb testing.TB) *Harness {
+	tb.Helper()
+
+	client := NewClient()
+	client.AddApplication(&sandwich_protobuf.SandwichApplication{
+		ApplicationIdentifier: DefaultApplication,
+		DisplayName:           DefaultApplication,
+		BotToken:              "test",
+	})
+
+	rest := NewREST()
+
+	// Logs written after the test has finished are discarded.
+	writer := &testWriter{tb: tb}
+	tb.Cleanup(writer.stop)
+
+	sw := sandwich.NewSandwich(nil, rest, writer)
+	sw.SandwichClient = client
+
+	bot := sandwich.NewBot(slog.New(slog.NewTextHandler(writer, nil)))
+	sw.RegisterBot(DefaultIdentifier, bot)
+
+	harness := &Harness{
+		tb: tb,
+
+		Client:   client,
+		REST:     rest,
+		Sandwich: sw,
+		Bot:      bot,
+	}
+
+	tb.Cleanup(harness.Close)
+
+	return harness
+}
+
+// Dispatch queues a payload to be handled by the bot of its identifier, as if it was
+// received from a message queue. Call Close to wait for queued events to be handled.
+func (h *Harness) Dispatch(payload sandwich_daemon.ProducedPayload) error {
+	return h.Sandwich.DispatchProducedPayload(context.Background(), payload)
+}
+
+// DispatchGRPC queues a payload to be handled as if it was received from gRPC. Sandwich
+// events such as SW_SHARD_STATUS_UPDATE are handled by the SandwichEvents of the Sandwich
+// and every other event by the bot of its identifier.
+func (h *Harness) DispatchGRPC(payload sandwich_daemon.ProducedPayload) error {
+	return h.Sandwich.DispatchGRPCPayload(context.Background(), payload)
+}
+
+// DispatchSync dispatches a payload to the bot of its identifier and returns once the
+// parser and every listener have finished, along with their errors.
+func (h *Harness) DispatchSync(payload sandwich_daemon.ProducedPayload) (*sandwich.DispatchResult, error) {
+	return h.Sandwich.DispatchProducedPayloadSync(context.Background(), payload)
+}
+
+// DispatchGRPCSync dispatches a payload as if it was received from gRPC and returns once
+// the parser and every listener have finished, along with their errors.
+func (h *Harness) DispatchGRPCSync(payload sandwich_daemon.ProducedPayload) (*sandwich.DispatchResult, error) {
+	return h.Sandwich.DispatchGRPCPayloadSync(context.Background(), payload)
+}
+
+// Close shuts down the Sandwich, waiting for queued events to be handled.
+func (h *Harness) Close() {
+	h.closeOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+
+		abandoned, err := h.Sandwich.Shutdown(ctx)
+		if err != nil {
+			h.tb.Errorf("sandwichtest: failed to shut down, abandoning %d events: %v", abandoned, err)
+		}
+	})
+}
+
+// testWriter writes logs to the test log.
+type testWriter struct {
+	mu sync.Mutex
+	tb testing.TB
+
+	done bool
+}
+
+func (w *testWriter) stop() {
+	w.mu.Lock()
+	w.done = true
+	w.mu.Unlock()
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.done {
+		w.tb.Log(string(p))
+	}
+
+	return len(p), nil
+}
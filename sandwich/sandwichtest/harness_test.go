@@ -0,0 +1,150 @@
+package sandwichtest_test
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	discord "github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+)
+
+func TestHarnessDispatchSync(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	harness.Client.AddGuild(discord.Guild{ID: guildID, Name: "guild"})
+	harness.Client.AddGuildMember(guildID, discord.GuildMember{User: &discord.User{ID: userID, Username: "user"}})
+
+	endpoint := discord.EndpointChannelMessages(channelID.String())
+
+	sandwich.On(harness.Bot.Handlers, sandwich.EventMessageCreate, func(eventCtx *sandwich.EventContext, message discord.Message) error {
+		grpcCtx := eventCtx.ToGRPCContext()
+
+		guild, err := sandwich.FetchGuild(grpcCtx, eventCtx.Guild)
+		if err != nil {
+			return err
+		}
+
+		member, err := sandwich.FetchGuildMember(grpcCtx, sandwich.NewGuildMember(&guild.ID, message.Author.ID))
+		if err != nil {
+			return err
+		}
+
+		_, err = discord.CreateMessage(eventCtx.Context, eventCtx.Session, message.ChannelID, discord.MessageParams{
+			Content: "hello " + member.User.Username + " from " + guild.Name,
+		})
+
+		return err
+	})
+
+	result, err := harness.DispatchSync(sandwichtest.MessageCreate(discord.Message{
+		ID:        1,
+		ChannelID: channelID,
+		GuildID:   new(guildID),
+		Author:    discord.User{ID: userID},
+	}))
+	if err != nil {
+		t.Fatalf("failed to dispatch: %v", err)
+	}
+
+	if result.Ignored || len(result.Errors) != 0 {
+		t.Fatalf("dispatch returned %+v, want no errors", result)
+	}
+
+	requests := harness.REST.RequestsTo(http.MethodPost, endpoint)
+	if len(requests) != 1 {
+		t.Fatalf("recorded %d requests, want 1", len(requests))
+	}
+
+	var params discord.MessageParams
+
+	err = requests[0].Decode(&params)
+	if err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+
+	if want := "hello user from guild"; params.Content != want {
+		t.Errorf("sent %q, want %q", params.Content, want)
+	}
+
+	if calls := harness.Client.CallsTo("FetchGuild"); len(calls) != 1 {
+		t.Errorf("recorded %d calls to FetchGuild, want 1", len(calls))
+	}
+}
+
+func TestHarnessDispatchSyncErrors(t *testing.T) {
+	harness := sandwichtest.New(t)
+	harness.Client.SetError("FetchGuild", errTest)
+
+	sandwich.On(harness.Bot.Handlers, sandwich.EventMessageCreate, func(eventCtx *sandwich.EventContext, _ discord.Message) error {
+		_, err := sandwich.FetchGuild(eventCtx.ToGRPCContext(), eventCtx.Guild)
+
+		return err
+	})
+
+	result, err := harness.DispatchSync(sandwichtest.MessageCreate(discord.Message{
+		ID:        1,
+		ChannelID: channelID,
+		GuildID:   new(guildID),
+	}))
+	if err != nil {
+		t.Fatalf("failed to dispatch: %v", err)
+	}
+
+	if len(result.Errors) != 1 {
+		t.Fatalf("dispatch returned %d errors, want 1", len(result.Errors))
+	}
+
+	if result.Errors[0].Event != discord.DiscordEventMessageCreate {
+		t.Errorf("error is for event %s, want %s", result.Errors[0].Event, discord.DiscordEventMessageCreate)
+	}
+}
+
+func TestHarnessDispatchGRPCEvents(t *testing.T) {
+	harness := sandwichtest.New(t)
+
+	var events []string
+
+	sandwich.On(harness.Sandwich.SandwichEvents, sandwich.EventSandwichGRPCConnected, func(_ *sandwich.EventContext, application string) error {
+		events = append(events, "connected "+application)
+
+		return nil
+	})
+
+	sandwich.On(harness.Sandwich.SandwichEvents, sandwich.EventSandwichGRPCDisconnected, func(_ *sandwich.EventContext, application, reason string) error {
+		events = append(events, "disconnected "+application+" "+reason)
+
+		return nil
+	})
+
+	sandwich.On(harness.Sandwich.SandwichEvents, sandwich.EventSandwichGRPCReconnecting,
+		func(_ *sandwich.EventContext, application string, attempt int, delay time.Duration) error {
+			events = append(events, fmt.Sprintf("reconnecting %s %d %v", application, attempt, delay))
+
+			return nil
+		})
+
+	for _, payload := range []sandwich_daemon.ProducedPayload{
+		sandwichtest.SandwichGRPCConnected("a"),
+		sandwichtest.SandwichGRPCDisconnected("a", "stream closed"),
+		sandwichtest.SandwichGRPCReconnecting("a", 2, time.Second),
+	} {
+		result, err := harness.DispatchGRPCSync(payload)
+		if err != nil {
+			t.Fatalf("failed to dispatch: %v", err)
+		}
+
+		if err := result.Err(); err != nil {
+			t.Fatalf("failed to handle %s: %v", payload.Type, err)
+		}
+	}
+
+	want := []string{"connected a", "disconnected a stream closed", "reconnecting a 2 1s"}
+	if !slices.Equal(events, want) {
+		t.Errorf("handled %v, want %v", events, want)
+	}
+}
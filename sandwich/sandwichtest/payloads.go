@@ -0,0 +1,445 @@
+package sandwichtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich_daemon "github.com/WelcomerTeam/Sandwich-Daemon"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+var (
+	// DefaultIdentifier is the identifier of payloads and of the bot created by New.
+	DefaultIdentifier = "test"
+
+	// DefaultApplication is the application of payloads and the application seeded by New.
+	DefaultApplication = "test"
+
+	// DefaultApplicationID is the application ID of payloads.
+	DefaultApplicationID = discord.Snowflake(1)
+)
+
+// PayloadOption changes a payload created by NewPayload.
+type PayloadOption func(payload *sandwich_daemon.ProducedPayload)
+
+// WithIdentifier sets the identifier, used to pick which bot receives the payload.
+func WithIdentifier(identifier string) PayloadOption {
+	return func(payload *sandwich_daemon.ProducedPayload) {
+		payload.Metadata.Identifier = identifier
+	}
+}
+
+// WithApplication sets the application the payload was received by.
+func WithApplication(application string, applicationID discord.Snowflake) PayloadOption {
+	return func(payload *sandwich_daemon.ProducedPayload) {
+		payload.Metadata.Application = application
+		payload.Metadata.ApplicationID = applicationID
+	}
+}
+
+// WithShard sets the shard the payload was received by.
+func WithShard(shardGroup, shardID, shardCount int32) PayloadOption {
+	return func(payload *sandwich_daemon.ProducedPayload) {
+		payload.Metadata.Shard = [3]int32{shardGroup, shardID, shardCount}
+	}
+}
+
+// WithExtra sets an extra of the payload. The value is marshalled to JSON.
+func WithExtra(key string, value any) PayloadOption {
+	return func(payload *sandwich_daemon.ProducedPayload) {
+		if payload.Extra == nil {
+			payload.Extra = make(map[string]json.RawMessage)
+		}
+
+		payload.Extra[key] = mustMarshal(value)
+	}
+}
+
+// WithTrace sets when the payload was published and dispatched by sandwich.
+func WithTrace(publishedAt time.Time) PayloadOption {
+	return func(payload *sandwich_daemon.ProducedPayload) {
+		payload.Trace = sandwich_daemon.Trace{
+			"publish":  publishedAt.UnixNano(),
+			"dispatch": publishedAt.UnixNano(),
+		}
+	}
+}
+
+// NewPayload creates a dispatch payload of an event type. The data is marshalled to JSON,
+// unless it is a json.RawMessage or []byte, which are used as is so malformed payloads
+// can be tested. Panics if the data cannot be marshalled.
+func NewPayload(eventType string, data any, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	var payload sandwich_daemon.ProducedPayload
+
+	payload.Op = discord.GatewayOpDispatch
+	payload.Type = eventType
+	payload.Data = mustMarshal(data)
+	payload.Metadata = sandwich_daemon.ProducedMetadata{
+		Identifier:    DefaultIdentifier,
+		Application:   DefaultApplication,
+		ApplicationID: DefaultApplicationID,
+		Shard:         [3]int32{0, 0, 1},
+	}
+
+	for _, opt := range opts {
+		opt(&payload)
+	}
+
+	return payload
+}
+
+// Marshal returns the payload as it is received from a message queue or gRPC.
+func Marshal(payload sandwich_daemon.ProducedPayload) []byte {
+	return mustMarshal(payload)
+}
+
+func marshalPayload(payload sandwich_daemon.ProducedPayload) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return data, nil
+}
+
+func mustMarshal(value any) json.RawMessage {
+	switch value := value.(type) {
+	case json.RawMessage:
+		return value
+	case []byte:
+		return value
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		panic(fmt.Sprintf("sandwichtest: failed to marshal payload: %v", err))
+	}
+
+	return data
+}
+
+// withBefore adds the "before" extra used by update events.
+func withBefore(before any, opts []PayloadOption) []PayloadOption {
+	return append([]PayloadOption{WithExtra("before", before)}, opts...)
+}
+
+// Discord Events.
+
+func Ready(ready discord.Ready, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventReady, ready, opts...)
+}
+
+func Resumed(resume discord.Resume, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventResumed, resume, opts...)
+}
+
+func ApplicationCommandCreate(command discord.ApplicationCommandCreate, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventApplicationCommandCreate, command, opts...)
+}
+
+func ApplicationCommandUpdate(command discord.ApplicationCommandUpdate, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventApplicationCommandUpdate, command, opts...)
+}
+
+func ApplicationCommandDelete(command discord.ApplicationCommandDelete, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventApplicationCommandDelete, command, opts...)
+}
+
+func ChannelCreate(channel discord.Channel, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventChannelCreate, channel, opts...)
+}
+
+func ChannelUpdate(before, after discord.Channel, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventChannelUpdate, after, withBefore(before, opts)...)
+}
+
+func ChannelDelete(channel discord.Channel, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventChannelDelete, channel, opts...)
+}
+
+func ChannelPinsUpdate(pins discord.ChannelPinsUpdate, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventChannelPinsUpdate, pins, opts...)
+}
+
+func EntitlementCreate(entitlement discord.Entitlement, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventEntitlementCreate, entitlement, opts...)
+}
+
+func EntitlementUpdate(entitlement discord.Entitlement, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventEntitlementUpdate, entitlement, opts...)
+}
+
+func EntitlementDelete(entitlement discord.Entitlement, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventEntitlementDelete, entitlement, opts...)
+}
+
+func ThreadCreate(thread discord.Channel, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventThreadCreate, thread, opts...)
+}
+
+func ThreadUpdate(before, after discord.Channel, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventThreadUpdate, after, withBefore(before, opts)...)
+}
+
+func ThreadDelete(thread discord.Channel, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventThreadDelete, thread, opts...)
+}
+
+func ThreadMemberUpdate(member discord.ThreadMemberUpdate, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventThreadMemberUpdate, member, opts...)
+}
+
+func ThreadMembersUpdate(members discord.ThreadMembersUpdate, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventThreadMembersUpdate, members, opts...)
+}
+
+// GuildCreate creates a GUILD_CREATE payload for a guild that was not lazy loaded or
+// previously unavailable, which is dispatched as GUILD_JOIN.
+func GuildCreate(guild discord.Guild, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildCreate, guild, opts...)
+}
+
+// GuildJoin creates the GUILD_CREATE payload dispatched as GUILD_JOIN.
+func GuildJoin(guild discord.Guild, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return GuildCreate(guild, opts...)
+}
+
+// GuildAvailable creates the GUILD_CREATE payload of a previously unavailable guild,
+// dispatched as GUILD_AVAILABLE.
+func GuildAvailable(guild discord.Guild, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return GuildCreate(guild, append([]PayloadOption{WithExtra("unavailable", true)}, opts...)...)
+}
+
+// GuildLazyCreate creates the GUILD_CREATE payload of a lazy loaded guild, which is not
+// dispatched to listeners.
+func GuildLazyCreate(guild discord.Guild, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return GuildCreate(guild, append([]PayloadOption{WithExtra("lazy", true)}, opts...)...)
+}
+
+func GuildUpdate(before, after discord.Guild, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildUpdate, after, withBefore(before, opts)...)
+}
+
+// GuildDelete creates a GUILD_DELETE payload, dispatched as GUILD_UNAVAILABLE if the
+// guild is unavailable or GUILD_LEAVE otherwise.
+func GuildDelete(guild discord.UnavailableGuild, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildDelete, guild, opts...)
+}
+
+// GuildLeave creates the GUILD_DELETE payload dispatched as GUILD_LEAVE, including the
+// guild as it was before leaving.
+func GuildLeave(guild discord.Guild, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return GuildDelete(discord.UnavailableGuild{ID: guild.ID, Unavailable: false}, withBefore(guild, opts)...)
+}
+
+// GuildUnavailable creates the GUILD_DELETE payload dispatched as GUILD_UNAVAILABLE.
+func GuildUnavailable(guildID discord.Snowflake, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return GuildDelete(discord.UnavailableGuild{ID: guildID, Unavailable: true}, opts...)
+}
+
+func GuildAuditLogEntryCreate(entry discord.GuildAuditLogEntryCreate, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildAuditLogEntryCreate, entry, opts...)
+}
+
+func GuildBanAdd(ban discord.GuildBanAdd, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildBanAdd, ban, opts...)
+}
+
+func GuildBanRemove(ban discord.GuildBanRemove, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildBanRemove, ban, opts...)
+}
+
+func GuildEmojisUpdate(guildID discord.Snowflake, before, after []discord.Emoji, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildEmojisUpdate, discord.GuildEmojisUpdate{
+		GuildID: guildID,
+		Emojis:  after,
+	}, withBefore(before, opts)...)
+}
+
+func GuildStickersUpdate(guildID discord.Snowflake, before, after []discord.Sticker, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildStickersUpdate, discord.GuildStickersUpdate{
+		GuildID:  guildID,
+		Stickers: after,
+	}, withBefore(before, opts)...)
+}
+
+func GuildIntegrationsUpdate(guildID discord.Snowflake, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildIntegrationsUpdate, discord.GuildIntegrationsUpdate{
+		GuildID: guildID,
+	}, opts...)
+}
+
+func GuildMemberAdd(member discord.GuildMember, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildMemberAdd, member, opts...)
+}
+
+func GuildMemberRemove(guildID discord.Snowflake, user discord.User, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildMemberRemove, discord.GuildMemberRemove{
+		GuildID: guildID,
+		User:    user,
+	}, opts...)
+}
+
+func GuildMemberUpdate(before, after discord.GuildMember, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildMemberUpdate, after, withBefore(before, opts)...)
+}
+
+func GuildRoleCreate(guildID discord.Snowflake, role discord.Role, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildRoleCreate, discord.GuildRoleCreate{
+		GuildID: guildID,
+		Role:    role,
+	}, opts...)
+}
+
+func GuildRoleUpdate(guildID discord.Snowflake, before, after discord.Role, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildRoleUpdate, discord.GuildRoleUpdate{
+		GuildID: guildID,
+		Role:    after,
+	}, withBefore(before, opts)...)
+}
+
+func GuildRoleDelete(guildID, roleID discord.Snowflake, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventGuildRoleDelete, discord.GuildRoleDelete{
+		GuildID: guildID,
+		RoleID:  roleID,
+	}, opts...)
+}
+
+func IntegrationCreate(integration discord.Integration, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventIntegrationCreate, integration, opts...)
+}
+
+func IntegrationUpdate(before, after discord.Integration, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventIntegrationUpdate, after, withBefore(before, opts)...)
+}
+
+func IntegrationDelete(integration discord.IntegrationDelete, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventIntegrationDelete, integration, opts...)
+}
+
+func InteractionCreate(interaction discord.Interaction, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventInteractionCreate, interaction, opts...)
+}
+
+func InviteCreate(invite discord.InviteCreate, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventInviteCreate, invite, opts...)
+}
+
+func InviteDelete(invite discord.InviteDelete, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventInviteDelete, invite, opts...)
+}
+
+func MessageCreate(message discord.Message, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventMessageCreate, message, opts...)
+}
+
+func MessageUpdate(before, after discord.Message, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventMessageUpdate, after, withBefore(before, opts)...)
+}
+
+func MessageDelete(message discord.MessageDelete, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventMessageDelete, message, opts...)
+}
+
+func MessageDeleteBulk(messages discord.MessageDeleteBulk, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventMessageDeleteBulk, messages, opts...)
+}
+
+func MessageReactionAdd(reaction discord.MessageReactionAdd, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventMessageReactionAdd, reaction, opts...)
+}
+
+func MessageReactionRemove(reaction discord.MessageReactionRemove, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventMessageReactionRemove, reaction, opts...)
+}
+
+func MessageReactionRemoveAll(reactions discord.MessageReactionRemoveAll, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventMessageReactionRemoveAll, reactions, opts...)
+}
+
+func MessageReactionRemoveEmoji(reactions discord.MessageReactionRemoveEmoji, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventMessageReactionRemoveEmoji, reactions, opts...)
+}
+
+func PresenceUpdate(presence discord.PresenceUpdate, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventPresenceUpdate, presence, opts...)
+}
+
+func StageInstanceCreate(stageInstance discord.StageInstance, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventStageInstanceCreate, stageInstance, opts...)
+}
+
+func StageInstanceUpdate(stageInstance discord.StageInstance, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventStageInstanceUpdate, stageInstance, opts...)
+}
+
+func StageInstanceDelete(stageInstance discord.StageInstance, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventStageInstanceDelete, stageInstance, opts...)
+}
+
+func TypingStart(typing discord.TypingStart, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventTypingStart, typing, opts...)
+}
+
+func UserUpdate(before, after discord.User, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventUserUpdate, after, withBefore(before, opts)...)
+}
+
+func VoiceStateUpdate(before, after discord.VoiceState, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventVoiceStateUpdate, after, withBefore(before, opts)...)
+}
+
+func VoiceServerUpdate(voiceServer discord.VoiceServerUpdate, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventVoiceServerUpdate, voiceServer, opts...)
+}
+
+func WebhookUpdate(guildID, channelID discord.Snowflake, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(discord.DiscordEventWebhookUpdate, discord.WebhookUpdate{
+		GuildID:   guildID,
+		ChannelID: channelID,
+	}, opts...)
+}
+
+// Sandwich Events.
+
+func SandwichConfigurationReload(opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(sandwich_daemon.SandwichEventConfigUpdate, nil, opts...)
+}
+
+func SandwichShardStatusUpdate(identifier string, shardID int32, status sandwich_daemon.ShardStatus, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(sandwich_daemon.SandwichShardStatusUpdate, sandwich_daemon.ShardStatusUpdateEvent{
+		Identifier: identifier,
+		ShardID:    shardID,
+		Status:     status,
+	}, opts...)
+}
+
+func SandwichApplicationStatusUpdate(identifier string, status sandwich_daemon.ApplicationStatus, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(sandwich_daemon.SandwichApplicationStatusUpdate, sandwich_daemon.ApplicationStatusUpdateEvent{
+		Identifier: identifier,
+		Status:     status,
+	}, opts...)
+}
+
+func SandwichGRPCConnected(application string, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(sandwich.SandwichEventGRPCConnected, sandwich.GRPCConnectedEvent{
+		Application: application,
+	}, opts...)
+}
+
+func SandwichGRPCDisconnected(application, reason string, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(sandwich.SandwichEventGRPCDisconnected, sandwich.GRPCDisconnectedEvent{
+		Application: application,
+		Error:       reason,
+	}, opts...)
+}
+
+func SandwichGRPCReconnecting(application string, attempt int, delay time.Duration, opts ...PayloadOption) sandwich_daemon.ProducedPayload {
+	return NewPayload(sandwich.SandwichEventGRPCReconnecting, sandwich.GRPCReconnectingEvent{
+		Application: application,
+		Attempt:     attempt,
+		Delay:       delay,
+	}, opts...)
+}
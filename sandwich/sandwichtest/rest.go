@@ -0,0 +1,173 @@
+package sandwichtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/WelcomerTeam/Discord/discord"
+)
+
+// Request is a request made to the REST interface.
+type Request struct {
+	Method      string
+	Endpoint    string
+	ContentType string
+	Body        []byte
+	Headers     http.Header
+	Token       string
+}
+
+// Decode unmarshals the JSON body of the request.
+func (r Request) Decode(out any) error {
+	err := json.Unmarshal(r.Body, out)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	return nil
+}
+
+// RESTHandlerFunc returns the response body of a request.
+type RESTHandlerFunc func(request Request) ([]byte, error)
+
+// REST is a discord.RESTInterface that records every request instead of sending it.
+// Requests without a response return an empty body, which is not unmarshalled.
+type REST struct {
+	mu sync.RWMutex
+
+	handlers map[string]RESTHandlerFunc
+	requests []Request
+
+	Debug bool
+}
+
+var _ discord.RESTInterface = (*REST)(nil)
+
+func NewREST() *REST {
+	return &REST{
+		mu: sync.RWMutex{},
+
+		handlers: make(map[string]RESTHandlerFunc),
+		requests: make([]Request, 0),
+	}
+}
+
+// Respond makes requests to an endpoint return the response, marshalled to JSON.
+func (r *REST) Respond(method, endpoint string, response any) {
+	body := mustMarshal(response)
+
+	r.HandleFunc(method, endpoint, func(Request) ([]byte, error) {
+		return body, nil
+	})
+}
+
+// RespondError makes requests to an endpoint return an error.
+func (r *REST) RespondError(method, endpoint string, err error) {
+	r.HandleFunc(method, endpoint, func(Request) ([]byte, error) {
+		return nil, err
+	})
+}
+
+// HandleFunc sets the func responding to requests to an endpoint.
+func (r *REST) HandleFunc(method, endpoint string, handler RESTHandlerFunc) {
+	r.mu.Lock()
+	r.handlers[method+" "+endpoint] = handler
+	r.mu.Unlock()
+}
+
+// Requests returns every request made, in the order they were made.
+func (r *REST) Requests() []Request {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.requests)
+}
+
+// RequestsTo returns every request made to an endpoint, in the order they were made.
+func (r *REST) RequestsTo(method, endpoint string) []Request {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	requests := make([]Request, 0)
+
+	for _, request := range r.requests {
+		if request.Method == method && request.Endpoint == endpoint {
+			requests = append(requests, request)
+		}
+	}
+
+	return requests
+}
+
+// Reset removes every recorded request. Responses are kept.
+func (r *REST) Reset() {
+	r.mu.Lock()
+	r.requests = make([]Request, 0)
+	r.mu.Unlock()
+}
+
+func (r *REST) Fetch(_ context.Context, session *discord.Session, method, endpoint, contentType string, body []byte, headers http.Header) ([]byte, error) {
+	request := Request{
+		Method:      method,
+		Endpoint:    endpoint,
+		ContentType: contentType,
+		Body:        slices.Clone(body),
+		Headers:     headers.Clone(),
+	}
+
+	if session != nil {
+		request.Token = session.Token
+	}
+
+	r.mu.Lock()
+	r.requests = append(r.requests, request)
+	handler := r.handlers[method+" "+endpoint]
+	r.mu.Unlock()
+
+	if handler == nil {
+		return nil, nil
+	}
+
+	return handler(request)
+}
+
+func (r *REST) FetchBJ(ctx context.Context, session *discord.Session, method, endpoint, contentType string, body []byte, headers http.Header, response any) error {
+	resp, err := r.Fetch(ctx, session, method, endpoint, contentType, body, headers)
+	if err != nil {
+		return err
+	}
+
+	if response != nil && len(resp) > 0 {
+		err = json.Unmarshal(resp, response)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *REST) FetchJJ(ctx context.Context, session *discord.Session, method, endpoint string, payload any, headers http.Header, response any) error {
+	var body []byte
+
+	if payload != nil {
+		var err error
+
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+	}
+
+	return r.FetchBJ(ctx, session, method, endpoint, "application/json", body, headers, response)
+}
+
+func (r *REST) SetDebug(value bool) {
+	r.mu.Lock()
+	r.Debug = value
+	r.mu.Unlock()
+}
@@ -0,0 +1,60 @@
+package sandwichtest_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	discord "github.com/WelcomerTeam/Discord/discord"
+	"github.com/WelcomerTeam/Sandwich/sandwich/sandwichtest"
+)
+
+func TestREST(t *testing.T) {
+	rest := sandwichtest.NewREST()
+	session := discord.NewSession("Bot test", rest)
+
+	endpoint := discord.EndpointChannelMessages(channelID.String())
+	rest.Respond(http.MethodPost, endpoint, discord.Message{ID: 1, Content: "hello"})
+
+	message, err := discord.CreateMessage(t.Context(), session, channelID, discord.MessageParams{Content: "hello"})
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+
+	if message.ID != 1 || message.Content != "hello" {
+		t.Errorf("returned message %d %q, want 1 %q", message.ID, message.Content, "hello")
+	}
+
+	requests := rest.RequestsTo(http.MethodPost, endpoint)
+	if len(requests) != 1 {
+		t.Fatalf("recorded %d requests, want 1", len(requests))
+	}
+
+	if requests[0].Token != "Bot test" {
+		t.Errorf("recorded token %q, want %q", requests[0].Token, "Bot test")
+	}
+
+	var params discord.MessageParams
+
+	err = requests[0].Decode(&params)
+	if err != nil {
+		t.Fatalf("failed to decode request: %v", err)
+	}
+
+	if params.Content != "hello" {
+		t.Errorf("recorded content %q, want %q", params.Content, "hello")
+	}
+
+	rest.RespondError(http.MethodPost, endpoint, errTest)
+
+	_, err = discord.CreateMessage(t.Context(), session, channelID, discord.MessageParams{Content: "hello"})
+	if !errors.Is(err, errTest) {
+		t.Errorf("CreateMessage returned %v, want %v", err, errTest)
+	}
+
+	rest.Reset()
+
+	if requests := rest.Requests(); len(requests) != 0 {
+		t.Errorf("recorded %d requests after Reset, want 0", len(requests))
+	}
+}
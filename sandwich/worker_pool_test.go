@@ -0,0 +1,366 @@
+package internal_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WelcomerTeam/Discord/discord"
+	sandwich "github.com/WelcomerTeam/Sandwich/sandwich"
+)
+
+// queuedEvents returns the number of events waiting in every worker queue.
+func queuedEvents(h *sandwich.Handlers) int {
+	queued := 0
+	for _, workerPool := range h.WorkerPools() {
+		queued += workerPool.Len
+	}
+
+	return queued
+}
+
+func TestDispatchQueueOverflow(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	bot.Handlers.SetQueueCapacity(1)
+	bot.Handlers.SetQueueOverflowPolicy(sandwich.OverflowPolicyDropNewest)
+
+	started := make(chan struct{}, 4)
+	release := make(chan struct{})
+
+	bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+		started <- struct{}{}
+		<-release
+
+		return nil
+	})
+
+	// The first event is being handled and the second has been taken from the queue,
+	// waiting for the worker, so the third fills the queue and the fourth is dropped.
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+	<-started
+
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+	for queuedEvents(bot.Handlers) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+	if dropped := bot.Handlers.DroppedEvents()[discord.DiscordEventResumed]; dropped != 1 {
+		t.Errorf("dropped %d events, want 1", dropped)
+	}
+
+	close(release)
+
+	for range 2 {
+		select {
+		case <-started:
+		case <-time.After(time.Second * 5):
+			t.Fatal("queued event was not handled")
+		}
+	}
+}
+
+func TestDroppedEventLogsQueuePolicy(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	logs := &syncBuffer{}
+	sandwichClient.Logger = slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	bot.Handlers.SetQueueCapacity(1)
+	bot.Handlers.SetQueueOverflowPolicy(sandwich.OverflowPolicyDropNewest)
+
+	started := make(chan struct{}, 4)
+	release := make(chan struct{})
+
+	defer close(release)
+
+	bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+		started <- struct{}{}
+		<-release
+
+		return nil
+	})
+
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+	<-started
+
+	// Queues keep the policy they were created with.
+	bot.Handlers.SetQueueOverflowPolicy(sandwich.OverflowPolicyDropOldest)
+
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+	for queuedEvents(bot.Handlers) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+	if want := "policy=" + sandwich.OverflowPolicyDropNewest.String(); !strings.Contains(logs.String(), want) {
+		t.Errorf("logs do not contain %q:\n%s", want, logs.String())
+	}
+}
+
+func TestQueueCapacityFromEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "", want: 0},
+		{value: "128", want: 128},
+		{value: "-1", want: 0},
+		{value: "lots", want: 0},
+	}
+
+	for _, test := range tests {
+		if got := sandwich.QueueCapacityFromEnv(test.value); got != test.want {
+			t.Errorf("QueueCapacityFromEnv(%q) returned %d, want %d", test.value, got, test.want)
+		}
+	}
+}
+
+func TestHandlersClose(t *testing.T) {
+	tests := []struct {
+		name          string
+		timeout       time.Duration
+		wantHandled   int
+		wantAbandoned int
+		wantErr       error
+	}{
+		{name: "drains", timeout: time.Second * 5, wantHandled: 4, wantAbandoned: 0, wantErr: nil},
+		{name: "abandons", timeout: time.Millisecond * 50, wantHandled: 2, wantAbandoned: 2, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sandwichClient, bot := newTestBot()
+
+			started := make(chan struct{}, 4)
+			release := make(chan struct{})
+
+			bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+				started <- struct{}{}
+				<-release
+
+				return nil
+			})
+
+			// The first event is being handled, the second is waiting for the worker and
+			// the rest are queued.
+			dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+			<-started
+
+			dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+			for queuedEvents(bot.Handlers) > 0 {
+				time.Sleep(time.Millisecond)
+			}
+
+			dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+			dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+			if test.wantAbandoned == 0 {
+				close(release)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), test.timeout)
+			defer cancel()
+
+			abandoned, err := bot.Handlers.Close(ctx)
+			if abandoned != test.wantAbandoned || !errors.Is(err, test.wantErr) {
+				t.Errorf("Close returned %d, %v, want %d, %v", abandoned, err, test.wantAbandoned, test.wantErr)
+			}
+
+			if test.wantAbandoned > 0 {
+				close(release)
+
+				// Waits for the event that was being handled.
+				_, err = bot.Handlers.Close(context.Background())
+				if err != nil {
+					t.Fatalf("failed to close handlers: %v", err)
+				}
+			}
+
+			if handled := len(started) + 1; handled != test.wantHandled {
+				t.Errorf("handled %d events, want %d", handled, test.wantHandled)
+			}
+		})
+	}
+}
+
+func TestDispatchAfterClose(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	handled := make(chan struct{}, 1)
+
+	bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+		handled <- struct{}{}
+
+		return nil
+	})
+
+	_, err := bot.Handlers.Close(context.Background())
+	if err != nil {
+		t.Fatalf("failed to close handlers: %v", err)
+	}
+
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+	select {
+	case <-handled:
+		t.Error("handled an event dispatched after the handlers were closed")
+	case <-time.After(time.Millisecond * 50):
+	}
+}
+
+func TestDispatchAfterCloseReturns(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	// Starts a worker so its closed queue is kept by Close.
+	dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+	_, err := bot.Handlers.Close(context.Background())
+	if err != nil {
+		t.Fatalf("failed to close handlers: %v", err)
+	}
+
+	dispatched := make(chan struct{})
+
+	go func() {
+		_ = sandwichClient.DispatchProducedPayload(context.Background(), newPayload(discord.DiscordEventResumed, discord.Resume{}))
+		close(dispatched)
+	}()
+
+	select {
+	case <-dispatched:
+	case <-time.After(time.Second * 5):
+		t.Fatal("Dispatch did not return after the handlers were closed")
+	}
+}
+
+func TestWorkerIdleTimeout(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+	bot.Handlers.SetWorkerIdleTimeout(time.Millisecond * 10)
+
+	handled := make(chan struct{}, 2)
+
+	bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+		handled <- struct{}{}
+
+		return nil
+	})
+
+	// The second event starts a new worker once the first has been retired.
+	for range 2 {
+		dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+		select {
+		case <-handled:
+		case <-time.After(time.Second * 5):
+			t.Fatal("event was not handled")
+		}
+
+		deadline := time.Now().Add(time.Second * 5)
+		for len(bot.Handlers.WorkerPools()) > 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("idle worker was not retired")
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestCloseApplicationWorkerPools(t *testing.T) {
+	sandwichClient, bot := newTestBot()
+
+	addTestApplication(sandwichClient, "a")
+	addTestApplication(sandwichClient, "b")
+
+	for _, key := range []sandwich.WorkerKey{{Application: "a", Partition: 0}, {Application: "a", Partition: 1}, {Application: "b", Partition: 0}} {
+		payload := newPayload(discord.DiscordEventResumed, discord.Resume{})
+		payload.Metadata.Application = key.Application
+		payload.Metadata.Shard = [3]int32{0, key.Partition, 2}
+
+		dispatch(t, sandwichClient, payload)
+	}
+
+	if closed := bot.Handlers.CloseApplicationWorkerPools("a"); closed != 2 {
+		t.Errorf("closed %d worker pools, want 2", closed)
+	}
+
+	workerPools := bot.Handlers.WorkerPools()
+	if len(workerPools) != 1 || workerPools[0].Key.Application != "b" {
+		t.Fatalf("worker pools %+v are left, want the worker pool of b", workerPools)
+	}
+
+	if !bot.Handlers.CloseWorkerPool(workerPools[0].Key) {
+		t.Errorf("failed to close the worker pool of b")
+	}
+
+	if bot.Handlers.CloseWorkerPool(workerPools[0].Key) {
+		t.Errorf("closed a worker pool that was already closed")
+	}
+}
+
+func TestPriorityLane(t *testing.T) {
+	tests := []struct {
+		name         string
+		priorityLane bool
+		wantHandled  bool
+	}{
+		{name: "enabled", priorityLane: true, wantHandled: true},
+		{name: "disabled", priorityLane: false, wantHandled: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sandwichClient, bot := newTestBot()
+			bot.Handlers.SetPriorityLane(test.priorityLane)
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			interactions := make(chan struct{}, 1)
+
+			bot.Handlers.RegisterOnResumedEvent(func(*sandwich.EventContext) error {
+				close(started)
+				<-release
+
+				return nil
+			})
+
+			bot.Handlers.RegisterOnInteractionCreateEvent(func(*sandwich.EventContext, discord.Interaction) error {
+				interactions <- struct{}{}
+
+				return nil
+			})
+
+			dispatch(t, sandwichClient, newPayload(discord.DiscordEventResumed, discord.Resume{}))
+
+			<-started
+
+			dispatch(t, sandwichClient, newPayload(discord.DiscordEventInteractionCreate, discord.Interaction{ID: 1}))
+
+			// Interactions are only handled whilst the partition is busy if they have their own lane.
+			select {
+			case <-interactions:
+				if !test.wantHandled {
+					t.Error("interaction was handled before the event queued ahead of it")
+				}
+			case <-time.After(time.Millisecond * 100):
+				if test.wantHandled {
+					t.Error("interaction was held back by the event queued ahead of it")
+				}
+			}
+
+			close(release)
+		})
+	}
+}